@@ -43,40 +43,78 @@ package accesslog
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 )
 
-var defaultFormat = " %s | %3d | %7v | %-7s | %-s "
-
 func New(opts ...Option) app.HandlerFunc {
-	return new(context.Background(), opts...)
+	handler, _ := new(context.Background(), opts...)
+	return handler
 }
 
 func NewWithContext(ctx context.Context, opts ...Option) app.HandlerFunc {
-	return new(ctx, opts...)
+	handler, _ := new(ctx, opts...)
+	return handler
 }
 
-func new(ctx context.Context, opts ...Option) app.HandlerFunc {
+// NewWithStop returns the middleware and a function that stops its background work and flushes
+// pending output. The stop function can be added to the OnShutdown hooks of the Hertz server:
+//
+//	mw, stop := accesslog.NewWithStop()
+//	h.Use(mw)
+//	h.OnShutdown = append(h.OnShutdown, stop)
+func NewWithStop(opts ...Option) (app.HandlerFunc, func(ctx context.Context)) {
+	return new(context.Background(), opts...)
+}
+
+func new(ctx context.Context, opts ...Option) (app.HandlerFunc, func(ctx context.Context)) {
 	cfg := newOptions(opts...)
-	// Check if format contains latency
-	cfg.enableLatency = strings.Contains(cfg.format, "${latency}")
+	if _, ok := latencyUnits[cfg.latencyUnit]; cfg.latencyUnit != "" && !ok {
+		panic("Unknown latency unit \"" + cfg.latencyUnit + "\"")
+	}
+	// Check if the start and stop time of requests are needed
+	cfg.enableLatency = cfg.levelPolicy != nil || cfg.sampler != nil
+	for _, tag := range []string{TagLatency, TagTime, TagCLFTime, TagStartTime, TagEndTime, TagUnix, TagUnixMilli, TagUnixNano} {
+		cfg.enableLatency = cfg.enableLatency || cfg.usesTag(tag)
+	}
+
+	if cfg.usesTag(TagConnReused) {
+		cfg.conns = newConnTracker()
+	}
+	for _, tag := range []string{TagUABrowser, TagUABrowserVersion, TagUAOS, TagUADevice, TagUABot} {
+		if cfg.uaCache == nil && cfg.usesTag(tag) {
+			cfg.uaCache = newLRU(uaCacheSize)
+		}
+	}
 
 	// Create correct time format
 	var timestamp atomic.Value
 	timestamp.Store(time.Now().In(cfg.timeZoneLocation).Format(cfg.timeFormat))
 
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	stop := func(ctx context.Context) {
+		cancel()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		if f, ok := cfg.output.(flusher); ok {
+			_ = f.Flush()
+		}
+	}
+
 	// Update date/time every 500 milliseconds in a separate go routine
-	if strings.Contains(cfg.format, "${time}") {
+	if cfg.cachedTime && cfg.usesTag(TagTime) {
 		go func() {
+			defer close(done)
 			for {
 				select {
 				case <-time.After(cfg.timeInterval):
@@ -86,6 +124,8 @@ func new(ctx context.Context, opts ...Option) app.HandlerFunc {
 				timestamp.Store(time.Now().In(cfg.timeZoneLocation).Format(cfg.timeFormat))
 			}
 		}()
+	} else {
+		close(done)
 	}
 
 	// Set PID once and add tag
@@ -99,26 +139,57 @@ func new(ctx context.Context, opts ...Option) app.HandlerFunc {
 
 	// instead of analyzing the template inside(handler) each time, this is done once before
 	// and we create several slices of the same length with the functions to be executed and fixed parts.
-	tmplChain, logFunChain, err := buildLogFuncChain(cfg, Tags)
+	tags, err := buildTags(cfg.customTags)
 	if err != nil {
 		panic(err)
 	}
+	if cfg.strictFormat && len(cfg.fields) == 0 {
+		if err = validateFormat(cfg.format, tags); err != nil {
+			panic(err)
+		}
+	}
+	tmplChain, logFunChain, err := buildLogFuncChain(cfg, tags)
+	if err != nil {
+		panic(err)
+	}
+
+	var fields []field
+	if len(cfg.fields) > 0 {
+		if fields, err = cfg.encoder.build(cfg.fields, tags); err != nil {
+			panic(err)
+		}
+	}
 
-	return func(ctx context.Context, c *app.RequestContext) {
+	handler := func(ctx context.Context, c *app.RequestContext) {
 		// Logger data
 		data := dataPool.Get().(*Data) //nolint:forcetypeassert,errcheck // We store nothing else in the pool
 		// no need for a reset, as long as we always override everything
 		data.Pid = pid
 		data.Timestamp = timestamp
+		data.cfg = cfg
+		data.RequestID = ""
+		data.Trace = TraceContext{}
+		data.userAgent = nil
 		// put data back in the pool
 		defer dataPool.Put(data)
 
+		if cfg.conns != nil {
+			data.connReused = cfg.conns.reused(c)
+		}
+
+		if cfg.requestID != nil {
+			ctx, data.RequestID = cfg.requestID.handle(ctx, c)
+		}
+
 		// Set latency start time
 		if cfg.enableLatency {
 			data.Start = time.Now()
 		}
 
-		c.Next(ctx)
+		if recovered := cfg.next(ctx, c); recovered != nil && cfg.repanic {
+			// raised again after the line is logged
+			defer panic(recovered)
+		}
 
 		if !cfg.logConditionFunc(ctx, c) {
 			return
@@ -128,45 +199,96 @@ func new(ctx context.Context, opts ...Option) app.HandlerFunc {
 			data.Stop = time.Now()
 		}
 
+		if cfg.traceExtractor != nil {
+			data.Trace = cfg.traceExtractor(ctx)
+		}
+
+		if cfg.sampler != nil && !cfg.sampler.Sample(c, data.Stop.Sub(data.Start)) {
+			return
+		}
+
+		level := hlog.LevelInfo
+		if cfg.levelPolicy != nil {
+			level = cfg.levelPolicy(c, data.Stop.Sub(data.Start))
+		}
+
 		// Get new buffer
 		buf := bytebufferpool.Get()
 		defer bytebufferpool.Put(buf)
 
-		if cfg.format == defaultTagFormat {
-			// format log to buffer
-			_, _ = buf.WriteString(fmt.Sprintf(defaultFormat,
-				timestamp,
-				c.Response.StatusCode(),
-				data.Stop.Sub(data.Start),
-				c.Method(),
-				c.Path(),
-			))
-
-			cfg.logFunc(ctx, buf.String())
+		if fields != nil {
+			if err := cfg.encoder.write(buf, fields, c, data); err != nil {
+				_, _ = buf.WriteString(err.Error())
+			}
+
+			cfg.writeLine(ctx, level, buf)
 			return
 		}
 
-		// Loop over template parts execute dynamic parts and add fixed parts to the buffer
-		for i, logFunc := range logFunChain {
-			if logFunc == nil {
-				_, _ = buf.Write(tmplChain[i]) //nolint:errcheck // This will never fail
-			} else if tmplChain[i] == nil {
-				_, err = logFunc(buf, c, data, "")
+		if cfg.format == defaultTagFormat {
+			if cfg.cachedTime {
+				buf.B = appendDefaultLine(buf.B, timestamp.Load().(string), c, data, cfg)
 			} else {
-				_, err = logFunc(buf, c, data, unsafeString(tmplChain[i]))
-			}
-			if err != nil {
-				break
+				buf.B = appendDefaultLine(buf.B, "", c, data, cfg)
 			}
+
+			cfg.writeLine(ctx, level, buf)
+			return
 		}
 
-		// Also write errors to the buffer
-		if err != nil {
+		// Loop over template parts execute dynamic parts and add fixed parts to the buffer
+		if err := writeChain(buf, tmplChain, logFunChain, c, data); err != nil {
+			// Also write errors to the buffer
 			_, _ = buf.WriteString(err.Error())
 		}
 
-		cfg.logFunc(ctx, buf.String())
+		cfg.writeLine(ctx, level, buf)
+	}
+
+	return handler, stop
+}
+
+// appendDefaultLine appends the line of the default format padded like " %s | %3d | %7v | %-7s | %-s ",
+// ts is the cached timestamp or empty to format the start time of the request
+func appendDefaultLine(dst []byte, ts string, c *app.RequestContext, data *Data, cfg *options) []byte {
+	dst = append(dst, ' ')
+	if ts != "" {
+		dst = append(dst, ts...)
+	} else {
+		dst = data.Start.In(cfg.timeZoneLocation).AppendFormat(dst, cfg.timeFormat)
+	}
+	var b [32]byte
+	dst = append(dst, " | "...)
+	dst = appendPadded(dst, strconv.AppendInt(b[:0], int64(c.Response.StatusCode()), 10), 3)
+	dst = append(dst, " | "...)
+	latency := data.Stop.Sub(data.Start)
+	if u, ok := latencyUnits[cfg.latencyUnit]; ok {
+		dst = appendPadded(dst, appendLatency(b[:0], latency, u), 7)
+	} else {
+		dst = appendPadded(dst, appendDuration(b[:0], latency), 7)
+	}
+	dst = append(dst, " | "...)
+	dst = appendPaddedLeft(dst, c.Method(), 7)
+	dst = append(dst, " | "...)
+	dst = append(dst, c.Path()...)
+	return append(dst, ' ')
+}
+
+// writeChain executes the dynamic parts of the template and adds the fixed parts to the buffer
+func writeChain(buf Buffer, tmplChain [][]byte, logFunChain []LogFunc, c *app.RequestContext, data *Data) (err error) {
+	for i, logFunc := range logFunChain {
+		if logFunc == nil {
+			_, _ = buf.Write(tmplChain[i]) //nolint:errcheck // This will never fail
+		} else if tmplChain[i] == nil {
+			_, err = logFunc(buf, c, data, "")
+		} else {
+			_, err = logFunc(buf, c, data, unsafeString(tmplChain[i]))
+		}
+		if err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func appendInt(output Buffer, v int) (int, error) {
@@ -46,8 +46,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/protocol"
 
@@ -64,6 +67,7 @@ func TestLogger(t *testing.T) {
 	buf := bytebufferpool.Get()
 	defer bytebufferpool.Put(buf)
 	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
 	engine := route.NewEngine(config.NewOptions([]config.Option{}))
 	engine.Use(New(WithFormat("${route}")))
 	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
@@ -77,6 +81,7 @@ func TestLoggerAll(t *testing.T) {
 	buf := bytebufferpool.Get()
 	defer bytebufferpool.Put(buf)
 	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
 	engine := route.NewEngine(config.NewOptions([]config.Option{}))
 	engine.Use(New(WithFormat("${pid}${reqHeaders}${resHeaders}${referer}${protocol}${ip}${ips}" +
 		"${host}${url}${ua}${body}${route}")))
@@ -91,6 +96,7 @@ func TestQueryParams(t *testing.T) {
 	buf := bytebufferpool.Get()
 	defer bytebufferpool.Put(buf)
 	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
 	engine := route.NewEngine(config.NewOptions([]config.Option{}))
 	engine.Use(New(WithFormat("${queryParams}")))
 	request := ut.PerformRequest(engine, "GET", "/?foo=bar&baz=moz", nil)
@@ -105,6 +111,7 @@ func TestRespBody(t *testing.T) {
 	buf := bytebufferpool.Get()
 	defer bytebufferpool.Put(buf)
 	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
 	engine := route.NewEngine(config.NewOptions([]config.Option{}))
 	engine.Use(New(WithFormat("${resBody}")))
 	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {
@@ -161,5 +168,167 @@ func Benchmark_AccessLog(b *testing.B) {
 		engine.GET("/", func(c context.Context, ctx *app.RequestContext) {
 			ctx.String(200, "hello world")
 		})
+		benchSetup(bb, engine)
+	})
+}
+
+// newAllocContext returns a handled request for the allocation tests and benchmarks,
+// c.Abort() keeps the handler index from overflowing when the context is reused
+func newAllocContext() *app.RequestContext {
+	c := app.NewContext(0)
+	c.Request.Header.SetMethod("GET")
+	c.Request.SetRequestURI("/ping?foo=bar&baz=moz")
+	c.Request.Header.Set("User-Agent", "curl/8.4.0")
+	c.Request.Header.Set("X-Request-Id", "abc-123")
+	c.Response.Header.Set("X-Test", "test")
+	c.String(200, "pong")
+	return c
+}
+
+var allocFormats = map[string][]Option{
+	"DefaultFormat":  nil,
+	"CachedTime":     {WithCachedTimestamp()},
+	"LatencyUnit":    {WithLatencyUnit("ms")},
+	"HeaderTags":     {WithFormat("${reqHeaders} ${resHeaders} ${resBody} ${queryParams}")},
+	"RequestTags":    {WithFormat("${status} ${latency} ${method} ${host} ${path} ${protocol} ${route}")},
+	"RedactedHeader": {WithFormat("${reqHeaders}"), WithRedaction(Redaction{Headers: []string{"X-Request-Id"}})},
+}
+
+func TestDefaultFormatPadding(t *testing.T) {
+	c := newAllocContext()
+	cfg := newOptions()
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, d := range []time.Duration{0, 999, 1500 * time.Nanosecond, 12 * time.Millisecond, 90 * time.Minute} {
+		data := &Data{Start: start, Stop: start.Add(d)}
+		want := fmt.Sprintf(" %s | %3d | %7v | %-7s | %-s ", "03:04:05", 200, d, "GET", "/ping")
+		assert.DeepEqual(t, want, string(appendDefaultLine(nil, "03:04:05", c, data, cfg)))
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	for name, opts := range allocFormats {
+		b.Run(name, func(b *testing.B) {
+			handler := New(append(opts, WithOutput(io.Discard))...)
+			c := newAllocContext()
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Abort()
+				handler(ctx, c)
+			}
+		})
+	}
+}
+
+func TestParameterizedTags(t *testing.T) {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithFormat("${reqHeader:X-Request-Id}|${respHeader:Content-Type}|${query:page}|" +
+		"${cookie:session}|${param:id}|${form:field}|${locals:user}|${locals:count}|${locals:missing}")))
+	engine.POST("/users/:id", func(ctx context.Context, c *app.RequestContext) {
+		c.Set("user", "alice")
+		c.Set("count", 3)
+		c.String(200, "ok")
 	})
+	request := ut.PerformRequest(engine, "POST", "/users/42?page=7",
+		&ut.Body{Body: strings.NewReader("field=value&other=1"), Len: -1},
+		ut.Header{Key: "X-Request-Id", Value: "abc-123"},
+		ut.Header{Key: "Cookie", Value: "session=s3cr3t; theme=dark"},
+		ut.Header{Key: "Content-Type", Value: "application/x-www-form-urlencoded"})
+	w := request.Result()
+	assert.DeepEqual(t, 200, w.StatusCode())
+	assert.True(t, strings.HasSuffix(buf.String(),
+		"abc-123|text/plain; charset=utf-8|7|s3cr3t|42|value|alice|3|\n"))
+}
+
+func TestParameterizedTagsMissing(t *testing.T) {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithFormat("[${reqHeader:X-Request-Id}${query:page}${cookie:session}${param:id}${form:field}]")))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	request := ut.PerformRequest(engine, "GET", "/", nil)
+	assert.DeepEqual(t, 200, request.Result().StatusCode())
+	assert.True(t, strings.HasSuffix(buf.String(), "[]\n"))
+}
+
+func TestNewWithStop(t *testing.T) {
+	waitGoroutines := func(n int) bool {
+		for i := 0; i < 100; i++ {
+			if runtime.NumGoroutine() <= n {
+				return true
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	before := runtime.NumGoroutine()
+	handler, stop := NewWithStop(WithFormat("${time} ${status}"), WithCachedTimestamp(),
+		WithTimeInterval(time.Millisecond))
+	assert.NotNil(t, handler)
+	assert.True(t, runtime.NumGoroutine() > before)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stop(ctx)
+	assert.True(t, waitGoroutines(before))
+	// stop is idempotent
+	stop(ctx)
+
+	// without a cached timestamp there is nothing to stop
+	_, stop = NewWithStop(WithFormat("${time} ${status}"))
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.OnShutdown = append(engine.OnShutdown, stop)
+	engine.OnShutdown[0](ctx)
+}
+
+func TestTimeTags(t *testing.T) {
+	loc := time.FixedZone("", 8*60*60)
+	start := time.Date(2023, time.March, 4, 5, 6, 7, 891234567, time.UTC)
+	data := &Data{
+		Start: start,
+		Stop:  start.Add(1500 * time.Microsecond),
+		cfg:   newOptions(WithTimeZoneLocation(loc)),
+	}
+	c := app.NewContext(0)
+	assert.DeepEqual(t, "13:06:07", renderFormat(t, "${time}", c, data))
+	assert.DeepEqual(t, "2023-03-04 13:06:07.891", renderFormat(t, "${time:2006-01-02 15:04:05.000}", c, data))
+	assert.DeepEqual(t, "2023-03-04T13:06:07.891234567+08:00", renderFormat(t, "${startTime}", c, data))
+	assert.DeepEqual(t, "2023-03-04T13:06:07.892734567+08:00", renderFormat(t, "${endTime}", c, data))
+	assert.DeepEqual(t, "1677906367 1677906367891 1677906367891234567",
+		renderFormat(t, "${unix} ${unixMilli} ${unixNano}", c, data))
+}
+
+func TestTimePerRequest(t *testing.T) {
+	var lines []string
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${time:15:04:05.000000000}"),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+			lines = append(lines, format)
+		}),
+	))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/", nil)
+	ut.PerformRequest(engine, "GET", "/", nil)
+	assert.DeepEqual(t, 2, len(lines))
+	assert.True(t, lines[0] <= lines[1])
+}
+
+func TestDefaultFormatTime(t *testing.T) {
+	var line string
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithTimeFormat("2006"), WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+		line = format
+	})))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/", nil)
+	assert.True(t, strings.HasPrefix(line, " "+strconv.Itoa(time.Now().Year())+" | 200 | "))
 }
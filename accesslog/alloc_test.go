@@ -0,0 +1,77 @@
+//go:build !race
+// +build !race
+
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+// The race detector instruments the code with allocations, so these tests only run without it.
+
+// allocsPerRequest returns the average allocations of logging a request with opts
+func allocsPerRequest(opts ...Option) float64 {
+	handler, stop := NewWithStop(opts...)
+	defer stop(context.Background())
+	c := newAllocContext()
+	ctx := context.Background()
+	return testing.AllocsPerRun(100, func() {
+		c.Abort()
+		handler(ctx, c)
+	})
+}
+
+// TestZeroAllocs covers the allocation-free paths, WithOutput and WithSink
+func TestZeroAllocs(t *testing.T) {
+	sink := WithSink(func(ctx context.Context, level hlog.Level, line []byte) {})
+	for name, opts := range allocFormats {
+		t.Run(name, func(t *testing.T) {
+			allocs := allocsPerRequest(append(opts, WithOutput(io.Discard))...)
+			assert.Assert(t, allocs == 0, allocs)
+			allocs = allocsPerRequest(append(opts, sink)...)
+			assert.Assert(t, allocs == 0, allocs)
+		})
+	}
+}
+
+// discardLogger drops the lines of the hlog functions without formatting them
+type discardLogger struct {
+	hlog.FullLogger
+}
+
+func (discardLogger) CtxInfof(ctx context.Context, format string, v ...interface{}) {}
+
+// TestLogFuncAllocs documents that the log functions are not allocation-free. The functions
+// set by WithAccessLogFunc get the line copied into a string, the hlog functions also get it
+// boxed in the arguments, before the logger formats and writes it.
+func TestLogFuncAllocs(t *testing.T) {
+	allocs := allocsPerRequest(WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {}))
+	assert.Assert(t, allocs <= 1, allocs)
+
+	logger := hlog.DefaultLogger()
+	hlog.SetLogger(discardLogger{})
+	defer hlog.SetLogger(logger)
+	allocs = allocsPerRequest()
+	assert.Assert(t, allocs <= 3, allocs)
+}
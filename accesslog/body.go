@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultTruncationMarker = "...(truncated)"
+
+// DefaultBodyContentTypes are the textual content types that are usually safe to log.
+var DefaultBodyContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+	"application/xml",
+	"text/",
+}
+
+// BodyCapture defines how ${body} and ${resBody} capture bodies. Streamed bodies are never
+// logged, so that logging never reads a stream the handler did not consume, and multipart
+// bodies are skipped once BodyCapture is set.
+type BodyCapture struct {
+	// MaxSize is the maximum number of body bytes written to a log line,
+	// longer bodies are cut and followed by TruncationMarker. 0 means unlimited.
+	//
+	// Optional. Default: 0
+	MaxSize int
+
+	// TruncationMarker is appended to truncated bodies.
+	//
+	// Optional. Default: ...(truncated)
+	TruncationMarker string
+
+	// ContentTypes are the media types of the bodies that are logged. An entry ending
+	// with "/" matches a whole type, e.g. "text/". Empty allows every content type.
+	//
+	// Optional. Default: nil
+	ContentTypes []string
+
+	// Base64 logs bodies that are not allowed by ContentTypes, or are not valid UTF-8 when
+	// ContentTypes is empty, base64 encoded instead of skipping them.
+	//
+	// Optional. Default: false
+	Base64 bool
+}
+
+// bodyCapture is BodyCapture with defaults applied, a nil *bodyCapture logs bodies in full
+type bodyCapture struct {
+	maxSize      int
+	marker       string
+	contentTypes []string
+	base64       bool
+}
+
+func newBodyCapture(b BodyCapture) *bodyCapture {
+	bc := &bodyCapture{
+		maxSize: b.MaxSize,
+		marker:  b.TruncationMarker,
+		base64:  b.Base64,
+	}
+	if bc.marker == "" {
+		bc.marker = defaultTruncationMarker
+	}
+	for _, ct := range b.ContentTypes {
+		bc.contentTypes = append(bc.contentTypes, strings.ToLower(ct))
+	}
+	return bc
+}
+
+// allowed reports whether bodies of the media type can be logged as they are
+func (b *bodyCapture) allowed(mediaType, body []byte) bool {
+	if len(b.contentTypes) == 0 {
+		return !b.base64 || utf8.Valid(body)
+	}
+	for _, ct := range b.contentTypes {
+		if strings.HasSuffix(ct, "/") {
+			if len(mediaType) >= len(ct) && bytes.EqualFold(mediaType[:len(ct)], unsafeBytes(ct)) {
+				return true
+			}
+		} else if bytes.EqualFold(mediaType, unsafeBytes(ct)) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBody writes a request or response body as configured by BodyCapture and Redaction
+func writeBody(output Buffer, data *Data, contentType, body []byte) (int, error) {
+	cfg := data.options()
+	b := cfg.bodyCapture
+	if b == nil {
+		return cfg.redactor.writeBody(output, contentType, body)
+	}
+
+	mediaType := contentType
+	if n := bytes.IndexByte(mediaType, ';'); n >= 0 {
+		mediaType = mediaType[:n]
+	}
+	mediaType = bytes.TrimSpace(mediaType)
+	if len(mediaType) >= len("multipart/") && bytes.EqualFold(mediaType[:len("multipart/")], []byte("multipart/")) {
+		return 0, nil
+	}
+
+	old := output.Len()
+	if !b.allowed(mediaType, body) {
+		if !b.base64 {
+			return 0, nil
+		}
+		if cfg.redactor.hasBodyFields() {
+			// the fields of a body that is not JSON or a form cannot be redacted
+			output.Set(cfg.redactor.appendMask(output.Bytes(), body))
+			return output.Len() - old, nil
+		}
+		truncated := b.maxSize > 0 && base64.StdEncoding.EncodedLen(len(body)) > b.maxSize
+		if truncated {
+			// only encode the bytes that fit into MaxSize
+			body = body[:base64.StdEncoding.DecodedLen(b.maxSize)]
+		}
+		dst := output.Bytes()
+		n := len(dst)
+		dst = append(dst, make([]byte, base64.StdEncoding.EncodedLen(len(body)))...)
+		base64.StdEncoding.Encode(dst[n:], body)
+		if truncated {
+			dst = append(dst, b.marker...)
+		}
+		output.Set(dst)
+		return output.Len() - old, nil
+	}
+
+	if cfg.redactor == nil && b.maxSize > 0 && len(body) > b.maxSize {
+		// avoid copying large bodies, keep one byte more than allowed so that they are marked as truncated
+		body = body[:b.maxSize+1]
+	}
+	_, _ = cfg.redactor.writeBody(output, contentType, body)
+	if b.maxSize > 0 && output.Len()-old > b.maxSize {
+		dst := output.Bytes()[:old+b.maxSize]
+		// do not cut a multi-byte character in half
+		start := len(dst) - 1
+		for start > old && len(dst)-start < utf8.UTFMax && !utf8.RuneStart(dst[start]) {
+			start--
+		}
+		if start >= old && !utf8.FullRune(dst[start:]) {
+			dst = dst[:start]
+		}
+		output.Set(append(dst, b.marker...))
+	}
+	return output.Len() - old, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestBodyCapture(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		capture     BodyCapture
+		redaction   *Redaction
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "unlimited",
+			capture:     BodyCapture{},
+			contentType: "text/plain",
+			body:        "hello world",
+			want:        "hello world",
+		},
+		{
+			name:        "truncated",
+			capture:     BodyCapture{MaxSize: 5},
+			contentType: "text/plain",
+			body:        "hello world",
+			want:        "hello...(truncated)",
+		},
+		{
+			name:        "exact size",
+			capture:     BodyCapture{MaxSize: 11, TruncationMarker: "~"},
+			contentType: "text/plain",
+			body:        "hello world",
+			want:        "hello world",
+		},
+		{
+			name:        "multi-byte character",
+			capture:     BodyCapture{MaxSize: 3, TruncationMarker: "~"},
+			contentType: "text/plain; charset=utf-8",
+			body:        "abéé",
+			want:        "ab~",
+		},
+		{
+			name:        "allowed content type",
+			capture:     BodyCapture{ContentTypes: DefaultBodyContentTypes},
+			contentType: "Text/HTML; charset=utf-8",
+			body:        "<p>hi</p>",
+			want:        "<p>hi</p>",
+		},
+		{
+			name:        "disallowed content type",
+			capture:     BodyCapture{ContentTypes: DefaultBodyContentTypes},
+			contentType: "image/png",
+			body:        "\x89PNG",
+			want:        "",
+		},
+		{
+			name:        "base64",
+			capture:     BodyCapture{ContentTypes: DefaultBodyContentTypes, Base64: true},
+			contentType: "application/octet-stream",
+			body:        "\x00\x01\x02\x03",
+			want:        "AAECAw==",
+		},
+		{
+			name:        "base64 truncated",
+			capture:     BodyCapture{MaxSize: 4, Base64: true, TruncationMarker: "~"},
+			contentType: "application/octet-stream",
+			body:        "\xff\x01\x02\x03",
+			want:        "/wEC~",
+		},
+		{
+			name:        "multipart",
+			capture:     BodyCapture{},
+			contentType: "multipart/form-data; boundary=x",
+			body:        "--x\r\n",
+			want:        "",
+		},
+		{
+			name:        "redacted then truncated",
+			capture:     BodyCapture{MaxSize: 16, TruncationMarker: "~"},
+			redaction:   &Redaction{BodyFields: []string{"password"}},
+			contentType: "application/json",
+			body:        `{"password":"hunter2","user":"bob"}`,
+			want:        `{"password":"***~`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []Option{WithBodyCapture(tc.capture)}
+			if tc.redaction != nil {
+				opts = append(opts, WithRedaction(*tc.redaction))
+			}
+			c := app.NewContext(0)
+			c.Request.SetMethod("POST")
+			c.Request.Header.SetContentTypeBytes([]byte(tc.contentType))
+			c.Request.SetBodyString(tc.body)
+			c.Response.Header.SetContentType(tc.contentType)
+			c.Response.SetBodyString(tc.body)
+			data := &Data{cfg: newOptions(opts...)}
+			assert.DeepEqual(t, tc.want+"|"+tc.want, renderFormat(t, "${body}|${resBody}", c, data))
+		})
+	}
+}
+
+func TestBodyStreamNotRead(t *testing.T) {
+	c := app.NewContext(0)
+	c.Request.SetBodyStream(strings.NewReader("request stream"), -1)
+	c.Response.SetBodyStream(bytes.NewReader([]byte("response stream")), 15)
+	data := &Data{cfg: newOptions(WithBodyCapture(BodyCapture{}))}
+	assert.DeepEqual(t, "|0||15", renderFormat(t, "${body}|${bytesReceived}|${resBody}|${bytesSent}", c, data))
+	assert.True(t, c.Request.IsBodyStream())
+	assert.True(t, c.Response.IsBodyStream())
+	assert.DeepEqual(t, "request stream", string(c.Request.Body()))
+}
+
+func TestBodyStreamWithoutCapture(t *testing.T) {
+	c := app.NewContext(0)
+	c.Request.SetBodyStream(strings.NewReader("request stream"), -1)
+	assert.DeepEqual(t, "request stream", renderFormat(t, "${body}", c, &Data{}))
+}
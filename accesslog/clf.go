@@ -0,0 +1,118 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"net"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Preset formats compatible with the log formats of Apache and NGINX, they can be passed to WithFormat.
+const (
+	// FormatCommon is the Apache Common Log Format: %h %l %u %t "%r" %>s %b
+	FormatCommon = `${remoteAddr} - ${remoteUser} [${clfTime}] "${requestLine}" ${status} ${clfBytesSent}`
+	// FormatCombined is the Apache Combined Log Format: %h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+	FormatCombined = FormatCommon + ` "${clfReferer}" "${clfUA}"`
+	// FormatNginxMain is the "main" log format of the default nginx.conf
+	FormatNginxMain = `${remoteAddr} - ${remoteUser} [${clfTime}] "${requestLine}" ${status} ${bytesSent} "${clfReferer}" "${clfUA}" "${clfForwardedFor}"`
+)
+
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// remoteIP returns the IP of the peer of the connection, or an empty string if it is unknown
+func remoteIP(c *app.RequestContext) string {
+	switch addr := c.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		if addr.IP == nil || addr.IP.IsUnspecified() {
+			return ""
+		}
+		return addr.IP.String()
+	default:
+		host, _, err := net.SplitHostPort(addr.String())
+		if err != nil {
+			return addr.String()
+		}
+		return host
+	}
+}
+
+// writeOrDash writes s, or "-" if s is empty
+func writeOrDash(output Buffer, s string) (int, error) {
+	if s == "" {
+		return output.WriteString("-")
+	}
+	return output.WriteString(s)
+}
+
+// writeCLFHeader writes the escaped request header, masked if it is redacted
+func writeCLFHeader(output Buffer, c *app.RequestContext, data *Data, key string) (int, error) {
+	rd := data.options().redactor
+	value := c.Request.Header.Peek(key)
+	if rd == nil {
+		return writeCLFEscaped(output, value)
+	}
+	if rd.header(unsafeBytes(key)) && len(value) > 0 {
+		value = rd.appendMask(nil, value)
+	}
+	old := output.Len()
+	_, _ = writeCLFEscaped(output, value)
+	rd.redactPatterns(output, old)
+	return output.Len() - old, nil
+}
+
+// writeCLFEscaped writes s escaped the way Apache escapes request line and header values,
+// or "-" if s is empty. Quotes and backslashes are escaped with a backslash, non-printable
+// bytes as \xhh, so a value can never terminate the quoted field it is written to.
+func writeCLFEscaped(output Buffer, s []byte) (int, error) {
+	if len(s) == 0 {
+		return output.WriteString("-")
+	}
+	return writeEscaped(output, s)
+}
+
+// writeEscaped writes s escaped like writeCLFEscaped, empty values are written as is
+func writeEscaped(output Buffer, s []byte) (int, error) {
+	old := output.Len()
+	dst := output.Bytes()
+	start := 0
+	for i, b := range s {
+		if b >= 0x20 && b < 0x7f && b != '"' && b != '\\' {
+			continue
+		}
+		dst = append(dst, s[start:i]...)
+		switch b {
+		case '"', '\\':
+			dst = append(dst, '\\', b)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		case '\v':
+			dst = append(dst, '\\', 'v')
+		default:
+			dst = append(dst, '\\', 'x', hex[b>>4], hex[b&0xf])
+		}
+		start = i + 1
+	}
+	output.Set(append(dst, s[start:]...))
+	return output.Len() - old, nil
+}
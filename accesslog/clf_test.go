@@ -0,0 +1,125 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+)
+
+type addrConn struct {
+	*mock.Conn
+	local, remote net.Addr
+}
+
+func (c *addrConn) LocalAddr() net.Addr  { return c.local }
+func (c *addrConn) RemoteAddr() net.Addr { return c.remote }
+
+// renderFormat renders format for c the same way the middleware does
+func renderFormat(t *testing.T, format string, c *app.RequestContext, data *Data) string {
+	t.Helper()
+	tmplChain, logFunChain, err := buildLogFuncChain(newOptions(WithFormat(format)), Tags)
+	assert.Nil(t, err)
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	assert.Nil(t, writeChain(buf, tmplChain, logFunChain, c, data))
+	return buf.String()
+}
+
+func newCLFContext() (*app.RequestContext, *Data) {
+	c := app.NewContext(0)
+	c.SetConn(&addrConn{
+		Conn:   mock.NewConn(""),
+		remote: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52341},
+	})
+	c.Request.Header.SetMethod("GET")
+	c.Request.Header.SetRequestURI("/apache_pb.gif?a=1")
+	c.Request.Header.SetProtocol("HTTP/1.0")
+	c.Request.SetBasicAuth("frank", "secret")
+	c.Response.SetStatusCode(200)
+	c.Response.SetBodyString(string(make([]byte, 2326)))
+
+	start := time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+	data := &Data{
+		Start: start,
+		Stop:  start.Add(time.Millisecond),
+		cfg:   newOptions(WithTimeZoneLocation(start.Location())),
+	}
+	return c, data
+}
+
+func TestFormatCommon(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t,
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 200 2326`,
+		renderFormat(t, FormatCommon, c, data))
+
+	c.Request.Header.Del("Authorization")
+	c.Response.ResetBody()
+	c.Response.SetStatusCode(304)
+	assert.DeepEqual(t,
+		`127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 304 -`,
+		renderFormat(t, FormatCommon, c, data))
+}
+
+func TestFormatCombined(t *testing.T) {
+	c, data := newCLFContext()
+	c.Request.Header.Set("Referer", "http://www.example.com/start.html")
+	c.Request.Header.Set("User-Agent", "Mozilla/4.08 [en] (Win98; I ;Nav)")
+	assert.DeepEqual(t,
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 200 2326 `+
+			`"http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+		renderFormat(t, FormatCombined, c, data))
+
+	c.Request.Header.Del("Referer")
+	c.Request.Header.Set("User-Agent", "evil\" \\agent\x01")
+	assert.DeepEqual(t,
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 200 2326 `+
+			`"-" "evil\" \\agent\x01"`,
+		renderFormat(t, FormatCombined, c, data))
+}
+
+func TestFormatNginxMain(t *testing.T) {
+	c, data := newCLFContext()
+	c.Request.Header.Set("User-Agent", "curl/7.79.1")
+	c.Request.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	c.Response.ResetBody()
+	assert.DeepEqual(t,
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 200 0 `+
+			`"-" "curl/7.79.1" "203.0.113.7, 10.0.0.1"`,
+		renderFormat(t, FormatNginxMain, c, data))
+}
+
+func TestRemoteAddrUnknown(t *testing.T) {
+	c := app.NewContext(0)
+	assert.DeepEqual(t, "-", renderFormat(t, "${remoteAddr}", c, &Data{}))
+}
+
+func TestBytesSentNegativeContentLength(t *testing.T) {
+	c := app.NewContext(0)
+	c.Response.SetBodyString("hello")
+	assert.DeepEqual(t, "5", renderFormat(t, "${bytesSent}", c, &Data{}))
+
+	c.Response.Header.SetContentLength(-1)
+	assert.DeepEqual(t, "5", renderFormat(t, "${bytesSent}", c, &Data{}))
+}
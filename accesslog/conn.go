@@ -0,0 +1,128 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"net"
+	"reflect"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+// connTrackerSize is the number of connections remembered for ${connReused}
+const connTrackerSize = 4096
+
+// tlsVersions are the names written by ${tlsVersion}
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS1.0",
+	tls.VersionTLS11: "TLS1.1",
+	tls.VersionTLS12: "TLS1.2",
+	tls.VersionTLS13: "TLS1.3",
+}
+
+// tlsState returns the state of the TLS connection of the request, ok is false on plaintext
+// connections
+func tlsState(c *app.RequestContext) (state tls.ConnectionState, ok bool) {
+	conn, ok := c.GetConn().(network.ConnTLSer)
+	if !ok {
+		return state, false
+	}
+	state = conn.ConnectionState()
+	return state, state.HandshakeComplete
+}
+
+// clientCert returns the leaf certificate sent by the client
+func clientCert(c *app.RequestContext) ([]byte, string, bool) {
+	state, ok := tlsState(c)
+	if !ok || len(state.PeerCertificates) == 0 {
+		return nil, "", false
+	}
+	cert := state.PeerCertificates[0]
+	return cert.Raw, cert.Subject.String(), true
+}
+
+func writeTLSVersion(output Buffer, c *app.RequestContext) (int, error) {
+	state, ok := tlsState(c)
+	if !ok {
+		return output.WriteString("-")
+	}
+	if name, ok := tlsVersions[state.Version]; ok {
+		return output.WriteString(name)
+	}
+	old := output.Len()
+	output.Set(append(output.Bytes(), "0x"...))
+	_, _ = appendHex(output, []byte{byte(state.Version >> 8), byte(state.Version)})
+	return output.Len() - old, nil
+}
+
+func writeClientFingerprint(output Buffer, c *app.RequestContext) (int, error) {
+	raw, _, ok := clientCert(c)
+	if !ok {
+		return output.WriteString("-")
+	}
+	sum := sha256.Sum256(raw)
+	return appendHex(output, sum[:])
+}
+
+// writeSocketAddr writes the ip:port of addr with the IP anonymized by an, or "-" if it is unknown
+func writeSocketAddr(output Buffer, addr net.Addr, an *ipAnonymizer) (int, error) {
+	if addr == nil {
+		return output.WriteString("-")
+	}
+	if tcp, ok := addr.(*net.TCPAddr); ok && (tcp.IP == nil || tcp.IP.IsUnspecified()) && tcp.Port == 0 {
+		return output.WriteString("-")
+	}
+	s := addr.String()
+	if an == nil || s == "" {
+		return writeOrDash(output, s)
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || an.mode == IPSuppress {
+		return an.writeIP(output, s)
+	}
+	return output.WriteString(net.JoinHostPort(string(an.appendIP(nil, unsafeBytes(host))), port))
+}
+
+// connTracker remembers the recent connections to tell whether a request arrived on a
+// connection that already served a request. A connection returns the same remote address
+// object for its whole life, so the tracker keys on the identity of that object: a new
+// connection from a reused source port has a new one, and the tracker only keeps the small
+// address reachable, not the closed connection and its buffers.
+type connTracker struct {
+	seen *lru
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{seen: newLRU(connTrackerSize)}
+}
+
+// reused records the connection of the request and reports whether it was seen before
+func (t *connTracker) reused(c *app.RequestContext) bool {
+	conn := c.GetConn()
+	if conn == nil {
+		return false
+	}
+	// only pointers identify the connection, equal values may belong to different ones
+	addr := conn.RemoteAddr()
+	if addr == nil || reflect.TypeOf(addr).Kind() != reflect.Ptr {
+		return false
+	}
+	return t.seen.add(addr, nil)
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+)
+
+const tlsFormat = "${tlsVersion} ${tlsCipher} ${tlsServerName} ${tlsALPN} ${tlsClientSubject} ${tlsClientFingerprint}"
+
+type tlsConn struct {
+	addrConn
+	state tls.ConnectionState
+}
+
+func (c *tlsConn) Handshake() error                     { return nil }
+func (c *tlsConn) ConnectionState() tls.ConnectionState { return c.state }
+
+func newClientCert(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client \"one\"", Organization: []string{"Example"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(raw)
+	assert.Nil(t, err)
+	return cert
+}
+
+func TestTLSTags(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t, "- - - - - -", renderFormat(t, tlsFormat, c, data))
+
+	state := tls.ConnectionState{
+		Version:            tls.VersionTLS13,
+		HandshakeComplete:  true,
+		CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+		ServerName:         "example.com",
+		NegotiatedProtocol: "h2",
+	}
+	c.SetConn(&tlsConn{state: state, addrConn: addrConn{Conn: mock.NewConn("")}})
+	assert.DeepEqual(t, "TLS1.3 TLS_AES_128_GCM_SHA256 example.com h2 - -", renderFormat(t, tlsFormat, c, data))
+
+	cert := newClientCert(t)
+	sum := sha256.Sum256(cert.Raw)
+	state.Version = tls.VersionTLS12
+	state.ServerName = ""
+	state.NegotiatedProtocol = ""
+	state.PeerCertificates = []*x509.Certificate{cert}
+	c.SetConn(&tlsConn{state: state, addrConn: addrConn{Conn: mock.NewConn("")}})
+	assert.DeepEqual(t, `TLS1.2 TLS_AES_128_GCM_SHA256 - - CN=client \\\"one\\\",O=Example `+fmt.Sprintf("%x", sum),
+		renderFormat(t, tlsFormat, c, data))
+
+	state.Version = 0x0305
+	c.SetConn(&tlsConn{state: state, addrConn: addrConn{Conn: mock.NewConn("")}})
+	assert.DeepEqual(t, "0x0305", renderFormat(t, "${tlsVersion}", c, data))
+
+	// the handshake did not complete
+	c.SetConn(&tlsConn{addrConn: addrConn{Conn: mock.NewConn("")}})
+	assert.DeepEqual(t, "- - - - - -", renderFormat(t, tlsFormat, c, data))
+}
+
+func TestSocketAddrTags(t *testing.T) {
+	c, data := newCLFContext()
+	c.SetConn(&addrConn{
+		Conn:   mock.NewConn(""),
+		remote: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 52341},
+		local:  &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443},
+	})
+	assert.DeepEqual(t, "[2001:db8::1]:52341 10.0.0.1:443", renderFormat(t, "${peerAddr} ${localAddr}", c, data))
+
+	c.SetConn(&addrConn{Conn: mock.NewConn(""), remote: &net.TCPAddr{}})
+	assert.DeepEqual(t, "- -", renderFormat(t, "${peerAddr} ${localAddr}", c, data))
+}
+
+func TestConnReused(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t, "-", renderFormat(t, "${connReused}", c, data))
+
+	conns := newConnTracker()
+	conn := &addrConn{
+		Conn:   mock.NewConn(""),
+		remote: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52341},
+		local:  &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8888},
+	}
+	c.SetConn(conn)
+	assert.False(t, conns.reused(c))
+	assert.True(t, conns.reused(c))
+
+	c.SetConn(&addrConn{Conn: conn.Conn, remote: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52342}, local: conn.local})
+	assert.False(t, conns.reused(c))
+
+	// the connection is closed and the client reconnects from the same source port
+	c.SetConn(&addrConn{
+		Conn:   mock.NewConn(""),
+		remote: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52341},
+		local:  &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8888},
+	})
+	assert.False(t, conns.reused(c))
+	assert.True(t, conns.reused(c))
+
+	data.cfg = newOptions()
+	data.cfg.conns = conns
+	data.connReused = true
+	assert.DeepEqual(t, "true", renderFormat(t, "${connReused}", c, data))
+}
+
+func TestConnTrackerReleasesConn(t *testing.T) {
+	conns := newConnTracker()
+	collected := make(chan struct{})
+	func() {
+		c := app.NewContext(0)
+		conn := &addrConn{Conn: mock.NewConn(""), remote: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52341}}
+		runtime.SetFinalizer(conn, func(*addrConn) { close(collected) })
+		c.SetConn(conn)
+		assert.False(t, conns.reused(c))
+		assert.True(t, conns.reused(c))
+	}()
+	// the closed connection is not reachable from the tracker
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			assert.DeepEqual(t, 1, conns.seen.order.Len())
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("the connection was kept alive by the tracker")
+}
+
+func TestLRU(t *testing.T) {
+	l := newLRU(2)
+	assert.False(t, l.add("a", 1))
+	assert.False(t, l.add("b", 2))
+	v, ok := l.get("a")
+	assert.True(t, ok)
+	assert.DeepEqual(t, 1, v)
+	// b is the least recently used
+	assert.False(t, l.add("c", 3))
+	_, ok = l.get("b")
+	assert.False(t, ok)
+	assert.True(t, l.add("a", 4))
+	v, _ = l.get("a")
+	assert.DeepEqual(t, 4, v)
+}
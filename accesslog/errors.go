@@ -0,0 +1,106 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// errorTypeNames are the names written by ${errorType}, in the order of the errors.ErrorType bits
+var errorTypeNames = []struct {
+	typ  errors.ErrorType
+	name string
+}{
+	{errors.ErrorTypeBind, "bind"},
+	{errors.ErrorTypeRender, "render"},
+	{errors.ErrorTypePrivate, "private"},
+	{errors.ErrorTypePublic, "public"},
+	{errors.ErrorTypeAny, "any"},
+}
+
+// writeError writes the message of err. The message is escaped in a text format to keep the line
+// intact, the structured encoders escape the values themselves and get the raw message.
+func writeError(output Buffer, data *Data, err *errors.Error) (int, error) {
+	if err.Err == nil {
+		return 0, nil
+	}
+	if len(data.options().fields) > 0 {
+		return output.WriteString(err.Err.Error())
+	}
+	return writeEscaped(output, unsafeBytes(err.Err.Error()))
+}
+
+// writeErrors writes the messages of all errors separated by "; "
+func writeErrors(output Buffer, data *Data, errs errors.ErrorChain) (int, error) {
+	old := output.Len()
+	for i, err := range errs {
+		if i > 0 {
+			_, _ = output.WriteString("; ")
+		}
+		_, _ = writeError(output, data, err)
+	}
+	return output.Len() - old, nil
+}
+
+// writeErrorType writes the names of the type bits of err joined by "|"
+func writeErrorType(output Buffer, err *errors.Error) (int, error) {
+	old := output.Len()
+	rest := err.Type
+	for _, t := range errorTypeNames {
+		if err.Type&t.typ == 0 {
+			continue
+		}
+		if output.Len() > old {
+			_, _ = output.WriteString("|")
+		}
+		_, _ = output.WriteString(t.name)
+		rest &^= t.typ
+	}
+	if rest != 0 {
+		if output.Len() > old {
+			_, _ = output.WriteString("|")
+		}
+		_, _ = appendInt(output, int(rest))
+	}
+	return output.Len() - old, nil
+}
+
+// next calls c.Next, with WithRecovery a panic of the handlers is recovered, recorded in c.Errors
+// and the response is aborted with a 500. It returns the recovered value.
+func (o *options) next(ctx context.Context, c *app.RequestContext) (recovered interface{}) {
+	if !o.recovery {
+		c.Next(ctx)
+		return nil
+	}
+	defer func() {
+		if recovered = recover(); recovered != nil {
+			c.AbortWithStatus(consts.StatusInternalServerError)
+			_ = c.Error(&errors.Error{
+				Err:  fmt.Errorf("panic: %v", recovered),
+				Type: errors.ErrorTypePrivate,
+				Meta: recovered,
+			})
+		}
+	}()
+	c.Next(ctx)
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	hertzerrors "github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const errorFormat = "${status} [${error}] [${errors}] [${errorType}]"
+
+func TestErrorTags(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t, "200 [] [] []", renderFormat(t, errorFormat, c, data))
+
+	_ = c.Error(errors.New("first"))
+	_ = c.Error(&hertzerrors.Error{Err: errors.New("bad \"json\"\nbody"), Type: hertzerrors.ErrorTypeBind | hertzerrors.ErrorTypePublic})
+	assert.DeepEqual(t, `200 [bad \"json\"\nbody] [first; bad \"json\"\nbody] [bind|public]`, renderFormat(t, errorFormat, c, data))
+
+	_ = c.Error(&hertzerrors.Error{Err: errors.New("custom"), Type: 1 << 10})
+	assert.DeepEqual(t, "1024", renderFormat(t, "${errorType}", c, data))
+}
+
+func TestErrorTagsEncoded(t *testing.T) {
+	var line []byte
+	sink := WithSink(func(ctx context.Context, level hlog.Level, b []byte) {
+		line = append(line[:0], b...)
+	})
+	for _, opts := range [][]Option{
+		{WithJSONFields(TagError, TagErrors)},
+		{WithECSFields()},
+	} {
+		engine := route.NewEngine(config.NewOptions([]config.Option{}))
+		engine.Use(New(append(opts, sink)...))
+		engine.GET("/", func(ctx context.Context, c *app.RequestContext) {
+			_ = c.Error(errors.New(`open "x"`))
+			c.String(500, "error")
+		})
+		ut.PerformRequest(engine, "GET", "/", nil)
+
+		var fields map[string]interface{}
+		assert.Nil(t, json.Unmarshal(line, &fields))
+		if e, ok := fields["error"].(map[string]interface{}); ok {
+			assert.DeepEqual(t, `open "x"`, e["message"])
+		} else {
+			assert.DeepEqual(t, `open "x"`, fields[TagError])
+			assert.DeepEqual(t, `open "x"`, fields[TagErrors])
+		}
+	}
+}
+
+func newRecoveryEngine(line *string, repanic bool) *route.Engine {
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat(errorFormat),
+		WithRecovery(repanic),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+			*line = format
+		}),
+	))
+	engine.GET("/panic", func(ctx context.Context, c *app.RequestContext) {
+		panic("boom")
+	})
+	engine.GET("/ok", func(ctx context.Context, c *app.RequestContext) {
+		c.String(200, "ok")
+	})
+	return engine
+}
+
+func TestRecovery(t *testing.T) {
+	var line string
+	engine := newRecoveryEngine(&line, false)
+
+	w := ut.PerformRequest(engine, "GET", "/panic", nil)
+	assert.DeepEqual(t, 500, w.Code)
+	assert.DeepEqual(t, "500 [panic: boom] [panic: boom] [private]", line)
+
+	w = ut.PerformRequest(engine, "GET", "/ok", nil)
+	assert.DeepEqual(t, 200, w.Code)
+	assert.DeepEqual(t, "200 [] [] []", line)
+}
+
+func TestRecoveryRepanic(t *testing.T) {
+	var line string
+	engine := newRecoveryEngine(&line, true)
+
+	assert.Panic(t, func() {
+		ut.PerformRequest(engine, "GET", "/panic", nil)
+	})
+	assert.DeepEqual(t, "500 [panic: boom] [panic: boom] [private]", line)
+}
+
+func TestWithoutRecovery(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+		t.Fatal("unexpected log line")
+	})))
+	engine.GET("/panic", func(ctx context.Context, c *app.RequestContext) {
+		panic("boom")
+	})
+	assert.Panic(t, func() {
+		ut.PerformRequest(engine, "GET", "/panic", nil)
+	})
+}
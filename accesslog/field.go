@@ -0,0 +1,87 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"errors"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// numericTags are rendered as numbers by structured encoders
+var numericTags = map[string]bool{
+	TagPid:           true,
+	TagStatus:        true,
+	TagLatency:       true,
+	TagBytesSent:     true,
+	TagBytesReceived: true,
+	TagRecvSize:      true,
+	TagUnix:          true,
+	TagUnixMilli:     true,
+	TagUnixNano:      true,
+
+	TagReadHeaderLatency: true,
+	TagReadBodyLatency:   true,
+	TagHandleLatency:     true,
+}
+
+// field is a single key of a structured log line
+type field struct {
+	key     string
+	logFunc LogFunc
+	param   string
+	numeric bool
+
+	// path and omitEmpty are only used by nested JSON presets
+	path      []string
+	omitEmpty bool
+}
+
+// buildFields resolves the tag names of a structured format the same way buildLogFuncChain
+// resolves the tags of a template, names can be plain tags or tags with parameters
+func buildFields(names []string, tagFunctions map[string]LogFunc) ([]field, error) {
+	fields := make([]field, 0, len(names))
+	for _, name := range names {
+		logFunc, param, ok := lookupTag(unsafeBytes(name), tagFunctions)
+		if !ok {
+			return nil, errors.New("Unknown tag \"" + name + "\" in fields")
+		}
+		tag := name
+		if param != nil {
+			tag = name[:len(name)-len(param)-len(paramSeparator)]
+		}
+		fields = append(fields, field{
+			key:     name,
+			logFunc: logFunc,
+			param:   string(param),
+			numeric: numericTags[tag],
+		})
+	}
+	return fields, nil
+}
+
+// encoder renders the fields of a structured format into a single line
+type encoder struct {
+	build func(names []string, tagFunctions map[string]LogFunc) ([]field, error)
+	write func(output Buffer, fields []field, c *app.RequestContext, data *Data) error
+}
+
+var (
+	jsonEncoder   = &encoder{build: buildJSONFields, write: writeJSON}
+	logfmtEncoder = &encoder{build: buildLogfmtFields, write: writeLogfmt}
+	ltsvEncoder   = &encoder{build: buildLTSVFields, write: writeLTSV}
+)
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// splitHost splits the Host of a request into host and port the way net.SplitHostPort does,
+// except that a missing or invalid port is returned as "" instead of an error and brackets
+// are removed from an IPv6 host without port, e.g. "[::1]" is split into "::1" and "".
+func splitHost(hostport string) (host, port string) {
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		if !validPort(p) {
+			p = ""
+		}
+		return h, p
+	}
+	if n := len(hostport); n > 1 && hostport[0] == '[' && hostport[n-1] == ']' {
+		return hostport[1 : n-1], ""
+	}
+	return hostport, ""
+}
+
+// validPort reports whether port is a decimal number between 0 and 65535
+func validPort(port string) bool {
+	if port == "" || len(port) > 5 {
+		return false
+	}
+	for i := 0; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return false
+		}
+	}
+	n, _ := strconv.Atoi(port)
+	return n <= 65535
+}
+
+// requestPort returns the port of the Host of the request, falling back to the port the
+// connection was accepted on and then to the default port of the scheme, or "" if unknown.
+// The port may point into the request and is only valid until the request is released.
+func requestPort(c *app.RequestContext) string {
+	if _, port := splitHost(unsafeString(c.Request.URI().Host())); port != "" {
+		return port
+	}
+	if conn := c.GetConn(); conn != nil && conn.LocalAddr() != nil {
+		if _, port, err := net.SplitHostPort(conn.LocalAddr().String()); err == nil && validPort(port) && port != "0" {
+			return port
+		}
+	}
+	if _, ok := tlsState(c); ok || string(c.Request.URI().Scheme()) == "https" {
+		return "443"
+	}
+	if string(c.Request.URI().Scheme()) == "http" {
+		return "80"
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func FuzzSplitHost(f *testing.F) {
+	for _, seed := range []string{"", "example.com", "example.com:8080", "[::1]:8080", "[::1]", "::1", "[", "]:", ":::", "a:b:c:"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, hostport string) {
+		host, port := splitHost(hostport)
+		assert.True(t, port == "" || validPort(port))
+		assert.True(t, len(host)+len(port) <= len(hostport))
+	})
+}
+
+func FuzzHostTags(f *testing.F) {
+	for _, seed := range []string{"", "example.com", "example.com:", "[::1]:8080", "[::1", "::1]:80", "1.2.3.4:99999", "\x00:\xff"} {
+		f.Add(seed)
+	}
+	configs := []*options{
+		newOptions(),
+		newOptions(WithIPAnonymization(IPAnonymization{})),
+		newOptions(WithIPAnonymization(IPAnonymization{Mode: IPHash, HashKey: func() []byte { return []byte("key") }})),
+	}
+	f.Fuzz(func(t *testing.T, host string) {
+		c := app.NewContext(0)
+		c.Request.Header.SetHostBytes([]byte(host))
+		c.Request.URI().SetHost(host)
+		for _, cfg := range configs {
+			renderFormat(t, "${ip} ${port} ${host} ${url}", c, &Data{cfg: cfg})
+		}
+	})
+}
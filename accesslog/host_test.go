@@ -0,0 +1,72 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+)
+
+func TestSplitHost(t *testing.T) {
+	for hostport, want := range map[string][2]string{
+		"":                   {"", ""},
+		"example.com":        {"example.com", ""},
+		"example.com:8080":   {"example.com", "8080"},
+		"example.com:":       {"example.com", ""},
+		"example.com:http":   {"example.com", ""},
+		"example.com:70000":  {"example.com", ""},
+		"127.0.0.1:80":       {"127.0.0.1", "80"},
+		"[::1]:8080":         {"::1", "8080"},
+		"[::1]":              {"::1", ""},
+		"::1":                {"::1", ""},
+		"[fe80::1%eth0]:443": {"fe80::1%eth0", "443"},
+		"a:b:c":              {"a:b:c", ""},
+		"[":                  {"[", ""},
+	} {
+		host, port := splitHost(hostport)
+		assert.DeepEqual(t, want, [2]string{host, port})
+	}
+}
+
+func TestHostTags(t *testing.T) {
+	c, data := newCLFContext()
+	c.SetConn(&addrConn{
+		Conn:  mock.NewConn(""),
+		local: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 8888},
+	})
+	for host, want := range map[string]string{
+		"example.com:8080": "example.com 8080",
+		"[::1]:9090":       "::1 9090",
+		"[::1]":            "::1 8888",
+		"example.com":      "example.com 8888",
+	} {
+		c.Request.URI().SetHost(host)
+		assert.DeepEqual(t, want, renderFormat(t, "${ip} ${port}", c, data))
+	}
+
+	// without a listener port the scheme decides
+	c.SetConn(&addrConn{Conn: mock.NewConn("")})
+	c.Request.URI().SetHost("example.com")
+	assert.DeepEqual(t, "80", renderFormat(t, "${port}", c, data))
+	c.Request.URI().SetScheme("https")
+	assert.DeepEqual(t, "443", renderFormat(t, "${port}", c, data))
+	c.Request.URI().SetScheme("ftp")
+	assert.DeepEqual(t, "", renderFormat(t, "${port}", c, data))
+}
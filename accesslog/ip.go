@@ -0,0 +1,222 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IPAnonymizationMode selects how IPAnonymization rewrites client addresses
+type IPAnonymizationMode int
+
+const (
+	// IPTruncate zeroes the last bits of the address, e.g. 203.0.113.77 becomes 203.0.113.0
+	IPTruncate IPAnonymizationMode = iota
+	// IPHash replaces the address with a truncated HMAC-SHA256 keyed by a rotating key, so the
+	// requests of a client can be correlated until the key changes
+	IPHash
+	// IPSuppress replaces the address with "-"
+	IPSuppress
+)
+
+const (
+	defaultIPv4Bits      = 8
+	defaultIPv6Bits      = 80
+	defaultIPKeyRotation = 24 * time.Hour
+	ipHashKeySize        = 32
+	ipHashLen            = 8
+	suppressedIP         = "-"
+)
+
+// IPAnonymization defines how ${ip}, ${ips}, ${clientIP}, ${remoteAddr}, ${peerAddr} and
+// ${clfForwardedFor} anonymize client addresses. Values that are not IP addresses are
+// hashed in IPHash mode and suppressed otherwise.
+type IPAnonymization struct {
+	// Mode selects truncation, hashing or suppression.
+	//
+	// Optional. Default: IPTruncate
+	Mode IPAnonymizationMode
+
+	// IPv4Bits and IPv6Bits are the number of trailing bits zeroed by IPTruncate.
+	//
+	// Optional. Default: 8 and 80, i.e. a /24 and a /48 network
+	IPv4Bits int
+	IPv6Bits int
+
+	// HashKey returns the key of IPHash, it is called for every address so the key can be rotated.
+	//
+	// Optional. Default: a random key replaced every KeyRotation
+	HashKey func() []byte
+
+	// KeyRotation is the lifetime of the default random key, keys are replaced at multiples of
+	// KeyRotation since the epoch, e.g. at midnight UTC for 24 hours.
+	//
+	// Optional. Default: 24h
+	KeyRotation time.Duration
+}
+
+// ipAnonymizer rewrites addresses as configured by IPAnonymization, a nil *ipAnonymizer keeps them
+type ipAnonymizer struct {
+	mode    IPAnonymizationMode
+	v4Mask  net.IPMask
+	v6Mask  net.IPMask
+	hashKey func() []byte
+}
+
+func newIPAnonymizer(a IPAnonymization) *ipAnonymizer {
+	if a.Mode < IPTruncate || a.Mode > IPSuppress {
+		panic("Unknown IP anonymization mode " + strconv.Itoa(int(a.Mode)))
+	}
+	if a.IPv4Bits == 0 {
+		a.IPv4Bits = defaultIPv4Bits
+	}
+	if a.IPv6Bits == 0 {
+		a.IPv6Bits = defaultIPv6Bits
+	}
+	if a.IPv4Bits < 0 || a.IPv4Bits > 8*net.IPv4len || a.IPv6Bits < 0 || a.IPv6Bits > 8*net.IPv6len {
+		panic("IP anonymization bits out of range")
+	}
+	if a.KeyRotation <= 0 {
+		a.KeyRotation = defaultIPKeyRotation
+	}
+	an := &ipAnonymizer{
+		mode:    a.Mode,
+		v4Mask:  net.CIDRMask(8*net.IPv4len-a.IPv4Bits, 8*net.IPv4len),
+		v6Mask:  net.CIDRMask(8*net.IPv6len-a.IPv6Bits, 8*net.IPv6len),
+		hashKey: a.HashKey,
+	}
+	if an.hashKey == nil {
+		an.hashKey = rotatingKey(a.KeyRotation, time.Now)
+	}
+	return an
+}
+
+// rotatingKey returns a function returning a random key that is replaced at multiples of interval
+func rotatingKey(interval time.Duration, now func() time.Time) func() []byte {
+	var (
+		mu      sync.Mutex
+		key     []byte
+		expires time.Time
+	)
+	return func() []byte {
+		t := now()
+		mu.Lock()
+		defer mu.Unlock()
+		if key == nil || !t.Before(expires) {
+			key = make([]byte, ipHashKeySize)
+			if _, err := rand.Read(key); err != nil {
+				panic("Cannot generate IP hash key: " + err.Error())
+			}
+			expires = t.Truncate(interval).Add(interval)
+		}
+		return key
+	}
+}
+
+// appendIP appends the anonymized form of addr, which may carry a port, e.g. "203.0.113.77:8080"
+func (a *ipAnonymizer) appendIP(dst, addr []byte) []byte {
+	addr = bytes.TrimSpace(addr)
+	if a.mode == IPSuppress || len(addr) == 0 {
+		return append(dst, suppressedIP...)
+	}
+	ip := parseIP(addr)
+	switch a.mode {
+	case IPHash:
+		value := addr
+		if ip != nil {
+			value = ip.To16()
+		}
+		mac := hmac.New(sha256.New, a.hashKey())
+		_, _ = mac.Write(value)
+		var sum [sha256.Size]byte
+		for _, b := range mac.Sum(sum[:0])[:ipHashLen] {
+			dst = append(dst, hex[b>>4], hex[b&0xf])
+		}
+		return dst
+	default:
+		if ip == nil {
+			return append(dst, suppressedIP...)
+		}
+		if v4 := ip.To4(); v4 != nil {
+			return append(dst, v4.Mask(a.v4Mask).String()...)
+		}
+		return append(dst, ip.Mask(a.v6Mask).String()...)
+	}
+}
+
+// appendList appends the anonymized addresses of a comma separated list like X-Forwarded-For
+func (a *ipAnonymizer) appendList(dst, list []byte) []byte {
+	if len(bytes.TrimSpace(list)) == 0 {
+		return dst
+	}
+	if a.mode == IPSuppress {
+		return append(dst, suppressedIP...)
+	}
+	for i := 0; ; i++ {
+		j := bytes.IndexByte(list, ',')
+		if i > 0 {
+			dst = append(dst, ", "...)
+		}
+		if j < 0 {
+			return a.appendIP(dst, list)
+		}
+		dst = a.appendIP(dst, list[:j])
+		list = list[j+1:]
+	}
+}
+
+// writeIP writes addr, anonymized unless a is nil. Empty addresses are written as is.
+func (a *ipAnonymizer) writeIP(output Buffer, addr string) (int, error) {
+	if a == nil || addr == "" {
+		return output.WriteString(addr)
+	}
+	old := output.Len()
+	output.Set(a.appendIP(output.Bytes(), unsafeBytes(addr)))
+	return output.Len() - old, nil
+}
+
+// writeList writes the comma separated addresses of list, anonymized unless a is nil
+func (a *ipAnonymizer) writeList(output Buffer, list []byte) (int, error) {
+	if a == nil {
+		return output.Write(list)
+	}
+	old := output.Len()
+	output.Set(a.appendList(output.Bytes(), list))
+	return output.Len() - old, nil
+}
+
+// parseIP parses an address with an optional port or brackets, or returns nil
+func parseIP(addr []byte) net.IP {
+	s := string(addr)
+	if ip := net.ParseIP(s); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return net.ParseIP(host)
+	}
+	if len(s) > 2 && s[0] == '[' && s[len(s)-1] == ']' {
+		return net.ParseIP(s[1 : len(s)-1])
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+)
+
+func TestIPTruncate(t *testing.T) {
+	an := newIPAnonymizer(IPAnonymization{})
+	for addr, want := range map[string]string{
+		"203.0.113.77":             "203.0.113.0",
+		" 203.0.113.77 ":           "203.0.113.0",
+		"203.0.113.77:8080":        "203.0.113.0",
+		"::ffff:203.0.113.77":      "203.0.113.0",
+		"2001:db8:85a3::8a2e:7334": "2001:db8:85a3::",
+		"[2001:db8:85a3::1]:443":   "2001:db8:85a3::",
+		"[2001:db8:85a3::1]":       "2001:db8:85a3::",
+		"unknown":                  "-",
+		"":                         "-",
+	} {
+		assert.DeepEqual(t, want, string(an.appendIP(nil, []byte(addr))))
+	}
+
+	an = newIPAnonymizer(IPAnonymization{IPv4Bits: 16, IPv6Bits: 128})
+	assert.DeepEqual(t, "203.0.0.0", string(an.appendIP(nil, []byte("203.0.113.77"))))
+	assert.DeepEqual(t, "::", string(an.appendIP(nil, []byte("2001:db8::1"))))
+}
+
+func TestIPHash(t *testing.T) {
+	key := []byte("key")
+	an := newIPAnonymizer(IPAnonymization{Mode: IPHash, HashKey: func() []byte { return key }})
+	hash := string(an.appendIP(nil, []byte("203.0.113.77")))
+	assert.DeepEqual(t, 2*ipHashLen, len(hash))
+	assert.DeepEqual(t, hash, string(an.appendIP(nil, []byte("::ffff:203.0.113.77"))))
+	assert.DeepEqual(t, hash, string(an.appendIP(nil, []byte("203.0.113.77:8080"))))
+	assert.NotEqual(t, hash, string(an.appendIP(nil, []byte("203.0.113.78"))))
+	assert.DeepEqual(t, 2*ipHashLen, len(an.appendIP(nil, []byte("unknown"))))
+
+	key = []byte("rotated")
+	assert.NotEqual(t, hash, string(an.appendIP(nil, []byte("203.0.113.77"))))
+}
+
+func TestIPSuppress(t *testing.T) {
+	an := newIPAnonymizer(IPAnonymization{Mode: IPSuppress})
+	assert.DeepEqual(t, "-", string(an.appendIP(nil, []byte("203.0.113.77"))))
+	assert.DeepEqual(t, "-", string(an.appendList(nil, []byte("203.0.113.77, 10.0.0.1"))))
+	assert.DeepEqual(t, "", string(an.appendList(nil, nil)))
+}
+
+func TestIPList(t *testing.T) {
+	an := newIPAnonymizer(IPAnonymization{})
+	assert.DeepEqual(t, "203.0.113.0, 2001:db8::, -, 10.0.0.0",
+		string(an.appendList(nil, []byte("203.0.113.77,2001:db8::1 , unknown,  10.0.0.1:80"))))
+	assert.DeepEqual(t, "", string(an.appendList(nil, []byte(" "))))
+}
+
+func TestRotatingKey(t *testing.T) {
+	now := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	key := rotatingKey(24*time.Hour, func() time.Time { return now })
+	first := key()
+	assert.DeepEqual(t, ipHashKeySize, len(first))
+	now = now.Add(59 * time.Minute)
+	assert.DeepEqual(t, first, key())
+	now = now.Add(time.Minute)
+	assert.NotEqual(t, string(first), string(key()))
+}
+
+func TestIPAnonymizationInvalid(t *testing.T) {
+	for _, a := range []IPAnonymization{{Mode: IPSuppress + 1}, {IPv4Bits: 33}, {IPv6Bits: -1}} {
+		func() {
+			defer func() {
+				assert.True(t, recover() != nil)
+			}()
+			WithIPAnonymization(a)
+		}()
+	}
+}
+
+func TestIPAnonymizationTags(t *testing.T) {
+	c, data := newCLFContext()
+	c.SetConn(&addrConn{
+		Conn:   mock.NewConn(""),
+		remote: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 52341},
+	})
+	c.Request.SetHost("203.0.113.77:8080")
+	c.Request.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.1")
+	format := "${ip} ${ips} ${remoteAddr} ${peerAddr} ${clfForwardedFor}"
+	assert.DeepEqual(t, "203.0.113.77 198.51.100.7, 10.0.0.1 2001:db8::1 [2001:db8::1]:52341 198.51.100.7, 10.0.0.1",
+		renderFormat(t, format, c, data))
+
+	data.cfg = newOptions(WithIPAnonymization(IPAnonymization{}))
+	assert.DeepEqual(t, "203.0.113.0 198.51.100.0, 10.0.0.0 2001:db8:: [2001:db8::]:52341 198.51.100.0, 10.0.0.0",
+		renderFormat(t, format, c, data))
+
+	data.cfg = newOptions(WithIPAnonymization(IPAnonymization{Mode: IPSuppress}))
+	assert.DeepEqual(t, "- - - - -", renderFormat(t, format, c, data))
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+)
+
+const hex = "0123456789abcdef"
+
+// fieldLatency writes the latency of the structured encoders in the unit set by WithLatencyUnit,
+// or in nanoseconds, so that it stays a number without the padding of ${latency}
+func fieldLatency(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+	if unit := data.options().latencyUnit; unit != "" {
+		return writeLatency(output, data.Stop.Sub(data.Start), unit)
+	}
+	return appendInt(output, int(data.Stop.Sub(data.Start)))
+}
+
+// buildJSONFields resolves the fields of the JSON format
+func buildJSONFields(names []string, tagFunctions map[string]LogFunc) ([]field, error) {
+	fields, err := buildFields(names, tagFunctions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		if fields[i].key == TagLatency {
+			fields[i].logFunc = fieldLatency
+		}
+	}
+	return fields, nil
+}
+
+// writeJSON renders the fields as a single JSON object, the output of numeric tags is written
+// as a number and everything else as an escaped string
+func writeJSON(output Buffer, fields []field, c *app.RequestContext, data *Data) error {
+	value := bytebufferpool.Get()
+	defer bytebufferpool.Put(value)
+
+	_ = output.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			_ = output.WriteByte(',')
+		}
+		output.Set(appendJSONString(output.Bytes(), unsafeBytes(f.key)))
+		_ = output.WriteByte(':')
+
+		value.Reset()
+		if _, err := f.logFunc(value, c, data, f.param); err != nil {
+			return err
+		}
+		if f.numeric && isJSONNumber(value.B) {
+			_, _ = output.Write(value.B)
+		} else {
+			output.Set(appendJSONString(output.Bytes(), value.B))
+		}
+	}
+	_ = output.WriteByte('}')
+	return nil
+}
+
+// isJSONNumber reports whether b is an integer or decimal number
+func isJSONNumber(b []byte) bool {
+	if len(b) > 0 && b[0] == '-' {
+		b = b[1:]
+	}
+	if len(b) == 0 || b[0] == '.' || b[len(b)-1] == '.' {
+		return false
+	}
+	dot := false
+	for _, ch := range b {
+		switch {
+		case ch >= '0' && ch <= '9':
+		case ch == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// appendJSONString appends s as a quoted JSON string, invalid UTF-8 is replaced by U+FFFD
+func appendJSONString(dst, s []byte) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRune(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript parsers
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
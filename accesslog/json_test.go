@@ -0,0 +1,97 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestJSONFields(t *testing.T) {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithJSONFields(TagStatus, TagLatency, TagMethod, TagPath, TagUA, TagBytesSent,
+		TagUnix, TagUnixMilli, TagUnixNano)))
+	engine.GET("/ping", func(ctx context.Context, c *app.RequestContext) {
+		c.String(200, "pong")
+	})
+	request := ut.PerformRequest(engine, "GET", "/ping", nil,
+		ut.Header{Key: "User-Agent", Value: "curl \"7.0\"\n\\"})
+	assert.DeepEqual(t, 200, request.Result().StatusCode())
+
+	line := buf.String()[strings.Index(buf.String(), "{"):]
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(line), &fields))
+	assert.DeepEqual(t, float64(200), fields[TagStatus])
+	assert.DeepEqual(t, float64(4), fields[TagBytesSent])
+	assert.DeepEqual(t, "GET", fields[TagMethod])
+	assert.DeepEqual(t, "/ping", fields[TagPath])
+	assert.DeepEqual(t, "curl \"7.0\"\n\\", fields[TagUA])
+	latency, ok := fields[TagLatency].(float64)
+	assert.True(t, ok)
+	assert.True(t, latency >= 0)
+	for _, tag := range []string{TagUnix, TagUnixMilli, TagUnixNano} {
+		_, ok = fields[tag].(float64)
+		assert.Assert(t, ok, tag, fields[tag])
+	}
+	assert.True(t, strings.Contains(line, `"unix":1`))
+}
+
+func TestJSONFieldsUnknownTag(t *testing.T) {
+	defer func() {
+		assert.NotNil(t, recover())
+	}()
+	New(WithJSONFields(TagStatus, "stauts"))
+}
+
+func TestAppendJSONString(t *testing.T) {
+	for s, want := range map[string]string{
+		"":                                "",
+		"plain":                           "plain",
+		`quote " and backslash \`:         `quote " and backslash \`,
+		"control \x00\x01\t\r\n\x1f":      "control \x00\x01\t\r\n\x1f",
+		"unicode \u2713 and \u2028\u2029": "unicode \u2713 and \u2028\u2029",
+		"invalid \xff\xfe utf-8":          "invalid \ufffd\ufffd utf-8",
+	} {
+		var decoded string
+		assert.Nil(t, json.Unmarshal(appendJSONString(nil, []byte(s)), &decoded))
+		assert.DeepEqual(t, want, decoded)
+	}
+}
+
+func TestIsJSONNumber(t *testing.T) {
+	for s, want := range map[string]bool{
+		"0": true, "200": true, "-1": true, "1.5": true,
+		"": false, "-": false, "1.": false, ".5": false, "1.2.3": false, " 1": false, "abc": false,
+	} {
+		assert.DeepEqual(t, want, isJSONNumber([]byte(s)))
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// latencyUnit is the divisor of a latency unit in nanoseconds and the number of decimals written
+type latencyUnit struct {
+	div      time.Duration
+	decimals int
+}
+
+// latencyUnits are the units supported by ${latency:<unit>} and WithLatencyUnit
+var latencyUnits = map[string]latencyUnit{
+	"ns": {time.Nanosecond, 0},
+	"us": {time.Microsecond, 3},
+	"ms": {time.Millisecond, 3},
+	"s":  {time.Second, 6},
+}
+
+var pow10 = [...]int64{1, 10, 100, 1000, 10000, 100000, 1000000}
+
+// writeLatency writes d as a plain number in unit with the fixed number of decimals of the unit
+func writeLatency(output Buffer, d time.Duration, unit string) (int, error) {
+	u, ok := latencyUnits[unit]
+	if !ok {
+		return 0, errors.New("Unknown latency unit \"" + unit + "\"")
+	}
+	old := output.Len()
+	output.Set(appendLatency(output.Bytes(), d, u))
+	return output.Len() - old, nil
+}
+
+func appendLatency(dst []byte, d time.Duration, u latencyUnit) []byte {
+	if d < 0 {
+		d = 0
+	}
+	scale := pow10[u.decimals]
+	v := int64(d) / (int64(u.div) / scale)
+	dst = appendUint(dst, int(v/scale))
+	if u.decimals == 0 {
+		return dst
+	}
+	dst = append(dst, '.')
+	frac := v % scale
+	for p := scale / 10; p > frac && p > 1; p /= 10 {
+		dst = append(dst, '0')
+	}
+	return appendUint(dst, int(frac))
+}
+
+// appendDuration appends d formatted like time.Duration.String, without allocating
+func appendDuration(dst []byte, d time.Duration) []byte {
+	var buf [32]byte
+	w := len(buf)
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+	if u < uint64(time.Second) {
+		var prec int
+		w--
+		buf[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			buf[w] = '0'
+			return append(dst, buf[w:]...)
+		case u < uint64(time.Microsecond):
+			prec = 0
+			buf[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			// U+00B5 micro sign
+			w--
+			copy(buf[w:], "\u00b5")
+		default:
+			prec = 6
+			buf[w] = 'm'
+		}
+		w, u = durationFrac(buf[:w], u, prec)
+		w = durationInt(buf[:w], u)
+	} else {
+		w--
+		buf[w] = 's'
+		w, u = durationFrac(buf[:w], u, 9)
+		w = durationInt(buf[:w], u%60)
+		u /= 60
+		if u > 0 {
+			w--
+			buf[w] = 'm'
+			w = durationInt(buf[:w], u%60)
+			u /= 60
+			if u > 0 {
+				w--
+				buf[w] = 'h'
+				w = durationInt(buf[:w], u)
+			}
+		}
+	}
+	if neg {
+		w--
+		buf[w] = '-'
+	}
+	return append(dst, buf[w:]...)
+}
+
+// durationFrac writes the fraction of v/10**prec to the end of buf, omitting trailing zeros
+func durationFrac(buf []byte, v uint64, prec int) (int, uint64) {
+	w := len(buf)
+	digits := false
+	for i := 0; i < prec; i++ {
+		digit := v % 10
+		digits = digits || digit != 0
+		if digits {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if digits {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// durationInt writes v to the end of buf
+func durationInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+		return w
+	}
+	for v > 0 {
+		w--
+		buf[w] = byte(v%10) + '0'
+		v /= 10
+	}
+	return w
+}
+
+// appendPadded appends s right-aligned in width runes like fmt's %<width>v
+func appendPadded(dst, s []byte, width int) []byte {
+	for n := utf8.RuneCount(s); n < width; n++ {
+		dst = append(dst, ' ')
+	}
+	return append(dst, s...)
+}
+
+// appendPaddedLeft appends s left-aligned in width runes like fmt's %-<width>s
+func appendPaddedLeft(dst, s []byte, width int) []byte {
+	dst = append(dst, s...)
+	for n := utf8.RuneCount(s); n < width; n++ {
+		dst = append(dst, ' ')
+	}
+	return dst
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestAppendLatency(t *testing.T) {
+	d := 1503*time.Millisecond + 42*time.Microsecond + 7
+	for unit, want := range map[string]string{
+		"ns": "1503042007",
+		"us": "1503042.007",
+		"ms": "1503.042",
+		"s":  "1.503042",
+	} {
+		assert.DeepEqual(t, want, string(appendLatency(nil, d, latencyUnits[unit])))
+	}
+	assert.DeepEqual(t, "0.005", string(appendLatency(nil, 5*time.Microsecond, latencyUnits["ms"])))
+	assert.DeepEqual(t, "0.000000", string(appendLatency(nil, -time.Second, latencyUnits["s"])))
+}
+
+func TestLatencyTag(t *testing.T) {
+	c, data := newCLFContext()
+	data.Stop = data.Start.Add(2500 * time.Microsecond)
+	assert.DeepEqual(t, "2.500 2500000", renderFormat(t, "${latency:ms} ${latency:ns}", c, data))
+
+	data.cfg = newOptions(WithLatencyUnit("us"))
+	assert.DeepEqual(t, "2500.000", renderFormat(t, "${latency}", c, data))
+
+	tmplChain, logFunChain, err := buildLogFuncChain(newOptions(WithFormat("${latency:h}")), Tags)
+	assert.Nil(t, err)
+	assert.NotNil(t, writeChain(&bytebufferpool.ByteBuffer{}, tmplChain, logFunChain, c, data))
+}
+
+func TestLatencyUnitOption(t *testing.T) {
+	var line string
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithLatencyUnit("ms"), WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+		line = format
+	})))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/", nil)
+	fields := strings.Split(line, " | ")
+	assert.DeepEqual(t, 5, len(fields))
+	latency := strings.TrimSpace(fields[2])
+	assert.True(t, strings.Contains(latency, "."))
+	assert.False(t, strings.HasSuffix(latency, "s"))
+
+	assert.Panic(t, func() { New(WithLatencyUnit("h")) })
+}
+
+func TestAppendDuration(t *testing.T) {
+	for _, d := range []time.Duration{
+		0, 1, 999, time.Microsecond, 1500 * time.Nanosecond, time.Millisecond, 12345678,
+		time.Second, 1500 * time.Millisecond, 61 * time.Second, 90 * time.Minute, 25*time.Hour + time.Nanosecond,
+		-1, -1500 * time.Microsecond, -2 * time.Hour, math.MaxInt64, math.MinInt64,
+	} {
+		assert.DeepEqual(t, d.String(), string(appendDuration(nil, d)))
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// LevelPolicy picks the level of the access log line of a request.
+type LevelPolicy func(c *app.RequestContext, latency time.Duration) hlog.Level
+
+// StatusLevelPolicy logs 5xx responses at LevelError, 4xx responses and requests slower than
+// slowThreshold at LevelWarn and everything else at LevelInfo. A slowThreshold of 0 disables
+// the latency check.
+func StatusLevelPolicy(slowThreshold time.Duration) LevelPolicy {
+	return func(c *app.RequestContext, latency time.Duration) hlog.Level {
+		switch status := c.Response.StatusCode(); {
+		case status >= 500:
+			return hlog.LevelError
+		case status >= 400:
+			return hlog.LevelWarn
+		case slowThreshold > 0 && latency >= slowThreshold:
+			return hlog.LevelWarn
+		default:
+			return hlog.LevelInfo
+		}
+	}
+}
+
+// defaultLevelFuncs route each level to hlog, LevelInfo uses the function set by WithAccessLogFunc.
+// LevelFatal is left out on purpose as hlog.CtxFatalf exits the process.
+var defaultLevelFuncs = map[hlog.Level]func(ctx context.Context, format string, v ...interface{}){
+	hlog.LevelTrace:  hlog.CtxTracef,
+	hlog.LevelDebug:  hlog.CtxDebugf,
+	hlog.LevelNotice: hlog.CtxNoticef,
+	hlog.LevelWarn:   hlog.CtxWarnf,
+	hlog.LevelError:  hlog.CtxErrorf,
+}
+
+// levelFunc returns the log function of level, falling back to logFunc. custom reports whether
+// the function was set by WithLevelFuncs or WithAccessLogFunc rather than being an hlog function.
+func (o *options) levelFunc(level hlog.Level) (f func(ctx context.Context, format string, v ...interface{}), custom bool) {
+	if f, ok := o.levelFuncs[level]; ok {
+		return f, true
+	}
+	if level != hlog.LevelInfo {
+		if f, ok := defaultLevelFuncs[level]; ok {
+			return f, false
+		}
+	}
+	return o.logFunc, o.customLogFunc
+}
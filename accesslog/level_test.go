@@ -0,0 +1,105 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestStatusLevelPolicy(t *testing.T) {
+	policy := StatusLevelPolicy(100 * time.Millisecond)
+	c := app.NewContext(0)
+	for _, tc := range []struct {
+		status  int
+		latency time.Duration
+		want    hlog.Level
+	}{
+		{200, time.Millisecond, hlog.LevelInfo},
+		{304, time.Millisecond, hlog.LevelInfo},
+		{200, 100 * time.Millisecond, hlog.LevelWarn},
+		{404, time.Millisecond, hlog.LevelWarn},
+		{500, time.Millisecond, hlog.LevelError},
+		{503, time.Second, hlog.LevelError},
+	} {
+		c.Response.SetStatusCode(tc.status)
+		assert.DeepEqual(t, tc.want, policy(c, tc.latency))
+	}
+
+	c.Response.SetStatusCode(200)
+	assert.DeepEqual(t, hlog.LevelInfo, StatusLevelPolicy(0)(c, time.Hour))
+}
+
+func TestLevelFuncs(t *testing.T) {
+	var got []string
+	record := func(name string) func(ctx context.Context, format string, v ...interface{}) {
+		return func(ctx context.Context, format string, v ...interface{}) {
+			got = append(got, name+" "+fmt.Sprintf(format, v...))
+		}
+	}
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${status}"),
+		WithAccessLogFunc(record("info")),
+		WithLevelPolicy(StatusLevelPolicy(0)),
+		WithLevelFuncs(map[hlog.Level]func(ctx context.Context, format string, v ...interface{}){
+			hlog.LevelWarn:  record("warn"),
+			hlog.LevelError: record("error"),
+		}),
+	))
+	engine.GET("/:status", func(ctx context.Context, c *app.RequestContext) {
+		var status int
+		_, _ = fmt.Sscan(c.Param("status"), &status)
+		c.Status(status)
+	})
+	for _, path := range []string{"/200", "/404", "/500"} {
+		ut.PerformRequest(engine, "GET", path, nil)
+	}
+	assert.DeepEqual(t, []string{"info 200", "warn 404", "error 500"}, got)
+}
+
+func TestLevelFuncFallback(t *testing.T) {
+	opts := newOptions()
+	for level, want := range map[hlog.Level]func(ctx context.Context, format string, v ...interface{}){
+		hlog.LevelInfo:  hlog.CtxInfof,
+		hlog.LevelWarn:  hlog.CtxWarnf,
+		hlog.LevelFatal: hlog.CtxInfof,
+	} {
+		f, custom := opts.levelFunc(level)
+		assert.DeepEqual(t, fmt.Sprintf("%p", want), fmt.Sprintf("%p", f))
+		assert.False(t, custom)
+	}
+
+	opts = newOptions(WithAccessLogFunc(hlog.CtxNoticef), WithLevelFuncs(map[hlog.Level]func(ctx context.Context, format string, v ...interface{}){
+		hlog.LevelError: hlog.CtxWarnf,
+	}))
+	for _, level := range []hlog.Level{hlog.LevelInfo, hlog.LevelError} {
+		_, custom := opts.levelFunc(level)
+		assert.True(t, custom)
+	}
+	_, custom := opts.levelFunc(hlog.LevelWarn)
+	assert.False(t, custom)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+)
+
+// buildLogfmtFields resolves the fields of the logfmt format, bytes that are not allowed in
+// keys are replaced by "_"
+func buildLogfmtFields(names []string, tagFunctions map[string]LogFunc) ([]field, error) {
+	fields, err := buildFields(names, tagFunctions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		if fields[i].key == TagLatency {
+			fields[i].logFunc = fieldLatency
+		}
+		fields[i].key = sanitizeKey(fields[i].key, func(b byte) bool {
+			return b > ' ' && b < utf8.RuneSelf && b != '=' && b != '"' && b != 0x7f
+		})
+	}
+	return fields, nil
+}
+
+// writeLogfmt renders the fields as space separated key=value pairs, values are quoted and
+// escaped when they contain spaces, "=", quotes, control characters or invalid UTF-8
+func writeLogfmt(output Buffer, fields []field, c *app.RequestContext, data *Data) error {
+	value := bytebufferpool.Get()
+	defer bytebufferpool.Put(value)
+
+	for i, f := range fields {
+		if i > 0 {
+			_ = output.WriteByte(' ')
+		}
+		_, _ = output.WriteString(f.key)
+		_ = output.WriteByte('=')
+
+		value.Reset()
+		if _, err := f.logFunc(value, c, data, f.param); err != nil {
+			return err
+		}
+		if logfmtNeedsQuote(value.B) {
+			output.Set(appendJSONString(output.Bytes(), value.B))
+		} else {
+			_, _ = output.Write(value.B)
+		}
+	}
+	return nil
+}
+
+var logfmtNull = []byte("null")
+
+func logfmtNeedsQuote(b []byte) bool {
+	if bytes.Equal(b, logfmtNull) {
+		return true
+	}
+	for _, ch := range b {
+		if ch <= ' ' || ch == '=' || ch == '"' || ch == 0x7f {
+			return true
+		}
+	}
+	return !utf8.Valid(b)
+}
+
+// sanitizeKey replaces the bytes of key that are not allowed by "_"
+func sanitizeKey(key string, allowed func(b byte) bool) string {
+	var b []byte
+	for i := 0; i < len(key); i++ {
+		if allowed(key[i]) {
+			continue
+		}
+		if b == nil {
+			b = []byte(key)
+		}
+		b[i] = '_'
+	}
+	if b == nil {
+		return key
+	}
+	return string(b)
+}
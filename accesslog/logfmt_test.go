@@ -0,0 +1,124 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestLogfmtFields(t *testing.T) {
+	var line string
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithLogfmtFields(TagStatus, TagMethod, TagPath, TagUA, "reqHeader:X-Empty", "query:q"),
+		WithSink(func(ctx context.Context, level hlog.Level, b []byte) {
+			line = string(b)
+		}),
+	))
+	engine.GET("/ping", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/ping?q=null", nil,
+		ut.Header{Key: "User-Agent", Value: "curl \"7.0\"\n a=b"})
+	assert.DeepEqual(t, `status=200 method=GET path=/ping ua="curl \"7.0\"\n a=b" reqHeader:X-Empty= query:q="null"`, line)
+}
+
+func TestLogfmtKeys(t *testing.T) {
+	fields, err := buildLogfmtFields([]string{"time:2006-01-02 15:04", "reqHeader:X=\"y\""}, Tags)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, "time:2006-01-02_15:04", fields[0].key)
+	assert.DeepEqual(t, "reqHeader:X__y_", fields[1].key)
+	assert.DeepEqual(t, "2006-01-02 15:04", fields[0].param)
+}
+
+func TestLogfmtValues(t *testing.T) {
+	c, data := newCLFContext()
+	fields, err := buildLogfmtFields([]string{"reqHeader:X-Value"}, Tags)
+	assert.Nil(t, err)
+	for v, want := range map[string]string{
+		"plain":        `reqHeader:X-Value=plain`,
+		"":             `reqHeader:X-Value=`,
+		"a b":          `reqHeader:X-Value="a b"`,
+		"a=b":          `reqHeader:X-Value="a=b"`,
+		`"q"`:          `reqHeader:X-Value="\"q\""`,
+		"tab\there":    `reqHeader:X-Value="tab\there"`,
+		"invalid \xff": `reqHeader:X-Value="invalid \ufffd"`,
+		"caf\u00e9":    "reqHeader:X-Value=caf\u00e9",
+		`back\slash`:   `reqHeader:X-Value=back\slash`,
+		"null":         `reqHeader:X-Value="null"`,
+		"del\x7fbyte":  `reqHeader:X-Value="del` + "\x7f" + `byte"`,
+	} {
+		c.Request.Header.Set("X-Value", v)
+		buf := bytebufferpool.Get()
+		assert.Nil(t, writeLogfmt(buf, fields, c, data))
+		assert.DeepEqual(t, want, buf.String())
+		bytebufferpool.Put(buf)
+	}
+}
+
+func TestLTSVFields(t *testing.T) {
+	var line string
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithLTSVFields(TagStatus, TagMethod, TagPath, TagUA, "reqHeader:X-Request-Id", TagReferer),
+		WithSink(func(ctx context.Context, level hlog.Level, b []byte) {
+			line = string(b)
+		}),
+	))
+	engine.GET("/ping", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/ping", nil,
+		ut.Header{Key: "User-Agent", Value: "curl\t7.0\\\n"},
+		ut.Header{Key: "X-Request-Id", Value: "abc"})
+	assert.DeepEqual(t, "status:200\tmethod:GET\tpath:/ping\tua:curl\\t7.0\\\\\\n\treqHeader_X-Request-Id:abc\treferer:-", line)
+}
+
+func TestFieldsLatency(t *testing.T) {
+	c, data := newCLFContext()
+	data.Stop = data.Start.Add(2116 * time.Nanosecond)
+	for _, tt := range []struct {
+		build func(names []string, tagFunctions map[string]LogFunc) ([]field, error)
+		write func(output Buffer, fields []field, c *app.RequestContext, data *Data) error
+		unit  string
+		want  string
+	}{
+		{buildLogfmtFields, writeLogfmt, "", "latency=2116"},
+		{buildLogfmtFields, writeLogfmt, "us", "latency=2.116"},
+		{buildLTSVFields, writeLTSV, "", "latency:2116"},
+		{buildLTSVFields, writeLTSV, "us", "latency:2.116"},
+	} {
+		data.cfg = newOptions(WithLatencyUnit(tt.unit))
+		fields, err := tt.build([]string{TagLatency}, Tags)
+		assert.Nil(t, err)
+		buf := bytebufferpool.Get()
+		assert.Nil(t, tt.write(buf, fields, c, data))
+		assert.DeepEqual(t, tt.want, buf.String())
+		bytebufferpool.Put(buf)
+	}
+}
+
+func TestFieldsUnknownTag(t *testing.T) {
+	assert.Panic(t, func() { New(WithLogfmtFields(TagStatus, "stauts")) })
+	assert.Panic(t, func() { New(WithLTSVFields(TagStatus, "stauts")) })
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a fixed size cache safe for concurrent use that evicts the least recently used entry
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	entries map[interface{}]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		entries: make(map[interface{}]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns the value of key and marks it as recently used
+func (l *lru) get(key interface{}) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.entries[key]; ok {
+		l.order.MoveToFront(e)
+		return e.Value.(*lruEntry).value, true
+	}
+	return nil, false
+}
+
+// add sets the value of key, evicting the least recently used entry when the cache is full.
+// It reports whether key was already present.
+func (l *lru) add(key, value interface{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.entries[key]; ok {
+		e.Value.(*lruEntry).value = value
+		l.order.MoveToFront(e)
+		return true
+	}
+	if l.order.Len() >= l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value})
+	return false
+}
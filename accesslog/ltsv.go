@@ -0,0 +1,93 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+)
+
+// buildLTSVFields resolves the fields of the LTSV format, labels may only contain
+// letters, digits, "_", "." and "-", other bytes are replaced by "_"
+func buildLTSVFields(names []string, tagFunctions map[string]LogFunc) ([]field, error) {
+	fields, err := buildFields(names, tagFunctions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		if fields[i].key == TagLatency {
+			fields[i].logFunc = fieldLatency
+		}
+		fields[i].key = sanitizeKey(fields[i].key, func(b byte) bool {
+			return b >= '0' && b <= '9' || b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' ||
+				b == '_' || b == '.' || b == '-'
+		})
+	}
+	return fields, nil
+}
+
+// writeLTSV renders the fields as tab separated label:value pairs, see http://ltsv.org.
+// Empty values are written as "-".
+func writeLTSV(output Buffer, fields []field, c *app.RequestContext, data *Data) error {
+	value := bytebufferpool.Get()
+	defer bytebufferpool.Put(value)
+
+	for i, f := range fields {
+		if i > 0 {
+			_ = output.WriteByte('\t')
+		}
+		_, _ = output.WriteString(f.key)
+		_ = output.WriteByte(':')
+
+		value.Reset()
+		if _, err := f.logFunc(value, c, data, f.param); err != nil {
+			return err
+		}
+		if len(value.B) == 0 {
+			_ = output.WriteByte('-')
+			continue
+		}
+		output.Set(appendLTSVValue(output.Bytes(), value.B))
+	}
+	return nil
+}
+
+// appendLTSVValue escapes tabs, newlines, backslashes and other control characters,
+// which would otherwise break the line into wrong fields or records
+func appendLTSVValue(dst, s []byte) []byte {
+	start := 0
+	for i, b := range s {
+		if b >= 0x20 && b != '\\' && b != 0x7f {
+			continue
+		}
+		dst = append(dst, s[start:i]...)
+		switch b {
+		case '\\':
+			dst = append(dst, '\\', '\\')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		default:
+			dst = append(dst, '\\', 'x', hex[b>>4], hex[b&0xf])
+		}
+		start = i + 1
+	}
+	return append(dst, s[start:]...)
+}
@@ -43,6 +43,8 @@ package accesslog
 
 import (
 	"context"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -65,20 +67,104 @@ type (
 		// Optional. Default: 15:04:05
 		timeFormat string
 
-		// timeInterval is the delay before the timestamp is updated
+		// timeInterval is the delay before the cached timestamp is updated
 		//
 		// Optional. Default: 500 * time.Millisecond
 		timeInterval time.Duration
 
-		// logFunc custom define log function
+		// cachedTime makes ${time} read a timestamp refreshed every timeInterval instead of
+		// formatting the time each request was received
+		//
+		// Optional. Default: false
+		cachedTime bool
+
+		// requestID reads or generates the ID of ${requestID} and propagates it
+		//
+		// Optional. Default: nil
+		requestID *requestID
+
+		// traceExtractor reads the trace context of ${traceID} and ${spanID} from the span in ctx
+		//
+		// Optional. Default: nil, the traceparent header is used
+		traceExtractor TraceExtractor
+
+		// recovery recovers panics of the handlers to log them as a 500, repanic panics again
+		// once the line is logged
+		//
+		// Optional. Default: false
+		recovery bool
+		repanic  bool
+
+		// output and sink receive the lines instead of logFunc
+		//
+		// Optional. Default: nil
+		output io.Writer
+		sink   Sink
+
+		// conns tracks the connections when ${connReused} is used
+		conns *connTracker
+
+		// uaCache caches the parsed User-Agent headers when a ${ua*} tag is used
+		uaCache *lru
+
+		// latencyUnit makes ${latency} print a plain number in ns, us, ms or s
+		//
+		// Optional. Default: "", latency is printed as a time.Duration
+		latencyUnit string
+
+		// logFunc custom define log function, customLogFunc is set when it was set by WithAccessLogFunc
 		//
 		// Optional. Default: hlog.CtxInfof
-		logFunc func(ctx context.Context, format string, v ...interface{})
+		logFunc       func(ctx context.Context, format string, v ...interface{})
+		customLogFunc bool
 
 		// timeZoneLocation can be specified time zone
 		//
 		// Optional. Default: time.Local
 		timeZoneLocation *time.Location
+
+		// fields are the tags written by encoder, e.g. as keys of a JSON object, instead of rendering format
+		//
+		// Optional. Default: nil
+		fields  []string
+		encoder *encoder
+
+		// redactor masks sensitive values
+		//
+		// Optional. Default: nil
+		redactor *redactor
+
+		// ipAnonymizer anonymizes the client addresses of the IP tags
+		//
+		// Optional. Default: nil
+		ipAnonymizer *ipAnonymizer
+
+		// bodyCapture limits the bodies written by ${body} and ${resBody}
+		//
+		// Optional. Default: nil
+		bodyCapture *bodyCapture
+
+		// levelPolicy picks the level of each line, levelFuncs are the log functions of the levels
+		//
+		// Optional. Default: nil, every line is logged by logFunc
+		levelPolicy LevelPolicy
+		levelFuncs  map[hlog.Level]func(ctx context.Context, format string, v ...interface{})
+
+		// sampler drops lines of requests that are not sampled
+		//
+		// Optional. Default: nil
+		sampler *Sampler
+
+		// customTags are merged with Tags for this instance only
+		//
+		// Optional. Default: nil
+		customTags []map[string]LogFunc
+
+		// strictFormat makes New panic with FormatErrors if the format contains unknown tags
+		//
+		// Optional. Default: false, unknown tags without parameter are dropped
+		strictFormat bool
+
 		enableLatency    bool
 		logConditionFunc logConditionFunc
 	}
@@ -86,7 +172,10 @@ type (
 	Option func(o *options)
 )
 
-var defaultTagFormat = "[${time}] ${status} - ${latency} ${method} ${path}"
+var (
+	defaultTagFormat = "[${time}] ${status} - ${latency} ${method} ${path}"
+	defaultOptions   = newOptions()
+)
 
 func newOptions(opts ...Option) *options {
 	cfg := &options{
@@ -107,6 +196,20 @@ func newOptions(opts ...Option) *options {
 	return cfg
 }
 
+// usesTag reports whether the configured format or fields contain tag, with or without parameter
+func (o *options) usesTag(tag string) bool {
+	if len(o.fields) > 0 {
+		for _, name := range o.fields {
+			if name == tag || strings.HasPrefix(name, tag+paramSeparator) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(o.format, startTag+tag+endTag) ||
+		strings.Contains(o.format, startTag+tag+paramSeparator)
+}
+
 // WithFormat set log format
 func WithFormat(s string) Option {
 	return func(o *options) {
@@ -121,17 +224,19 @@ func WithTimeFormat(s string) Option {
 	}
 }
 
-// WithTimeInterval set timestamp refresh interval
+// WithTimeInterval set timestamp refresh interval, see WithCachedTimestamp
 func WithTimeInterval(t time.Duration) Option {
 	return func(o *options) {
 		o.timeInterval = t
 	}
 }
 
-// WithAccessLogFunc set print log function
+// WithAccessLogFunc set print log function, the line is passed as format with "%" escaped to "%%"
+// and without arguments, use WithSink to receive the line as is
 func WithAccessLogFunc(f func(ctx context.Context, format string, v ...interface{})) Option {
 	return func(o *options) {
 		o.logFunc = f
+		o.customLogFunc = true
 	}
 }
 
@@ -148,3 +253,174 @@ func WithLogConditionFunc(f logConditionFunc) Option {
 		o.logConditionFunc = f
 	}
 }
+
+// WithJSONFields set tags to be logged as a JSON object, format is ignored when set
+func WithJSONFields(fields ...string) Option {
+	return func(o *options) {
+		o.fields = fields
+		o.encoder = jsonEncoder
+	}
+}
+
+// WithECSFields log each request as a JSON object with the HTTP fields of the Elastic Common Schema,
+// e.g. http.request.method, url.path and http.response.status_code, format is ignored when set
+func WithECSFields() Option {
+	return func(o *options) {
+		o.fields = presetTags(ecsPreset)
+		o.encoder = newPresetEncoder(ecsPreset)
+	}
+}
+
+// WithGCPFields log each request as a JSON object with the severity, time and httpRequest fields of
+// a Google Cloud Logging entry, format is ignored when set. With a projectID the trace and span of
+// the request are linked to Cloud Trace.
+func WithGCPFields(projectID string) Option {
+	return func(o *options) {
+		preset := gcpPreset(projectID)
+		o.fields = presetTags(preset)
+		o.encoder = newPresetEncoder(preset)
+	}
+}
+
+// WithLogfmtFields set tags to be logged as logfmt key=value pairs, format is ignored when set
+func WithLogfmtFields(fields ...string) Option {
+	return func(o *options) {
+		o.fields = fields
+		o.encoder = logfmtEncoder
+	}
+}
+
+// WithLTSVFields set tags to be logged as LTSV label:value pairs, format is ignored when set
+func WithLTSVFields(fields ...string) Option {
+	return func(o *options) {
+		o.fields = fields
+		o.encoder = ltsvEncoder
+	}
+}
+
+// WithRedaction set headers, query arguments, body fields and patterns to be masked
+func WithRedaction(r Redaction) Option {
+	return func(o *options) {
+		o.redactor = newRedactor(r)
+	}
+}
+
+// WithIPAnonymization truncate, hash or suppress the addresses written by ${ip}, ${ips}, ${clientIP},
+// ${remoteAddr}, ${peerAddr} and ${clfForwardedFor}, every address of an X-Forwarded-For list is
+// anonymized. WithIPAnonymization panics if the mode or the number of bits is invalid.
+func WithIPAnonymization(a IPAnonymization) Option {
+	an := newIPAnonymizer(a)
+	return func(o *options) {
+		o.ipAnonymizer = an
+	}
+}
+
+// WithBodyCapture set size limit and allowed content types of logged bodies
+func WithBodyCapture(b BodyCapture) Option {
+	return func(o *options) {
+		o.bodyCapture = newBodyCapture(b)
+	}
+}
+
+// WithLevelPolicy set the policy that picks the log level of each request, see StatusLevelPolicy
+func WithLevelPolicy(p LevelPolicy) Option {
+	return func(o *options) {
+		o.levelPolicy = p
+	}
+}
+
+// WithLevelFuncs set the log functions used for the levels picked by the level policy,
+// levels without a function are logged by hlog
+func WithLevelFuncs(funcs map[hlog.Level]func(ctx context.Context, format string, v ...interface{})) Option {
+	return func(o *options) {
+		o.levelFuncs = funcs
+	}
+}
+
+// WithSampler set the sampler that decides which requests are logged
+func WithSampler(s *Sampler) Option {
+	return func(o *options) {
+		o.sampler = s
+	}
+}
+
+// WithCustomTags set tags that are only available to this middleware instance,
+// New panics if a name collides with a built-in tag or another custom tag
+func WithCustomTags(tags map[string]LogFunc) Option {
+	return func(o *options) {
+		o.customTags = append(o.customTags, tags)
+	}
+}
+
+// WithStrictFormat make New panic with FormatErrors listing every unknown tag,
+// unterminated "${" and missing parameter of the format, see also Validate
+func WithStrictFormat() Option {
+	return func(o *options) {
+		o.strictFormat = true
+	}
+}
+
+// WithCachedTimestamp make ${time} print a timestamp refreshed every time interval by a
+// goroutine instead of the time each request was received, which saves formatting the time
+// per request at the cost of precision
+func WithCachedTimestamp() Option {
+	return func(o *options) {
+		o.cachedTime = true
+	}
+}
+
+// WithLatencyUnit set the unit of ${latency} and of the default format to "ns", "us", "ms" or "s",
+// latencies are then printed as plain numbers instead of durations like "1.5ms"
+func WithLatencyUnit(unit string) Option {
+	return func(o *options) {
+		o.latencyUnit = unit
+	}
+}
+
+// WithRequestID read the request ID from the request header or generate one, set it on the
+// response and store it in the context passed to the next handlers and to the log function
+func WithRequestID(r RequestID) Option {
+	return func(o *options) {
+		o.requestID = newRequestID(r)
+	}
+}
+
+// WithTraceExtractor read ${traceID}, ${spanID}, ${traceFlags} and ${traceState} from the span
+// stored in the request context, requests without a valid span fall back to the traceparent header
+func WithTraceExtractor(e TraceExtractor) Option {
+	return func(o *options) {
+		o.traceExtractor = e
+	}
+}
+
+// WithRecovery recover panics of the next handlers, the response is aborted with a 500 and the panic
+// value is added to c.Errors for ${error}. With repanic the panic is raised again once the line is
+// logged, so an outer recovery middleware still handles it.
+func WithRecovery(repanic bool) Option {
+	return func(o *options) {
+		o.recovery = true
+		o.repanic = repanic
+	}
+}
+
+// WithOutput write the lines followed by a newline directly to w instead of passing them to the log
+// function, the log function and the level policy are then not used. Writes are serialized unless
+// w is an AsyncWriter, use NewAsyncWriter to keep a slow writer off the request path.
+func WithOutput(w io.Writer) Option {
+	return func(o *options) {
+		if _, ok := w.(*AsyncWriter); !ok && w != nil {
+			w = &lockedWriter{w: w}
+		}
+		o.output = w
+		o.sink = nil
+	}
+}
+
+// WithSink pass each line with its level to s instead of the log function, the line is passed
+// as data and never interpreted as a format string
+func WithSink(s Sink) Option {
+	return func(o *options) {
+		o.sink = s
+		o.output = nil
+	}
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const (
+	defaultAsyncQueueSize     = 1024
+	defaultAsyncBatchSize     = 32 << 10
+	defaultAsyncFlushInterval = time.Second
+)
+
+// ErrAsyncWriterClosed is returned by writes to a closed AsyncWriter
+var ErrAsyncWriterClosed = errors.New("accesslog: async writer closed")
+
+// asyncBufferPool holds the copies of the queued lines, separate from the pool of the
+// handlers as the buffers are released by another goroutine
+var asyncBufferPool bytebufferpool.Pool
+
+// lockedWriter serializes the writes of the handlers to a writer that may not be safe for
+// concurrent use
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// Sink receives each rendered line with the level picked by the level policy, or LevelInfo.
+// The line is only valid during the call and must be copied to be retained.
+type Sink func(ctx context.Context, level hlog.Level, line []byte)
+
+// writeLine sends the rendered line to the output set by WithOutput, the sink set by WithSink,
+// or to the log functions
+func (o *options) writeLine(ctx context.Context, level hlog.Level, buf *bytebufferpool.ByteBuffer) {
+	switch {
+	case o.output != nil:
+		_ = buf.WriteByte('\n')
+		_, _ = o.output.Write(buf.B)
+	case o.sink != nil:
+		o.sink(ctx, level, buf.B)
+	default:
+		o.logLine(ctx, level, buf.B)
+	}
+}
+
+// logLine passes the line to the function set by WithAccessLogFunc or the function of the level.
+// Those functions take a format string: the functions set by the user get the line as format
+// with "%" escaped, as they did before WithSink, and the hlog functions, which print a format
+// without arguments verbatim, get it as the argument of "%s".
+func (o *options) logLine(ctx context.Context, level hlog.Level, line []byte) {
+	logFunc, custom := o.logFunc, o.customLogFunc
+	if o.levelPolicy != nil {
+		logFunc, custom = o.levelFunc(level)
+	}
+	if custom {
+		logFunc(ctx, escapePercent(line))
+		return
+	}
+	// copied, as the logger may keep its arguments
+	logFunc(ctx, "%s", string(line))
+}
+
+func escapePercent(line []byte) string {
+	if bytes.IndexByte(line, '%') < 0 {
+		return string(line)
+	}
+	return strings.ReplaceAll(string(line), "%", "%%")
+}
+
+// flusher is implemented by outputs that buffer lines, such as AsyncWriter
+type flusher interface {
+	Flush() error
+}
+
+// AsyncConfig defines the queue and batching of an AsyncWriter.
+type AsyncConfig struct {
+	// QueueSize is the number of lines waiting to be written.
+	//
+	// Optional. Default: 1024
+	QueueSize int
+
+	// Block makes writes wait for room in a full queue instead of dropping the line.
+	//
+	// Optional. Default: false
+	Block bool
+
+	// BatchSize is the number of bytes collected before they are written to the underlying writer.
+	//
+	// Optional. Default: 32 KiB
+	BatchSize int
+
+	// FlushInterval writes the collected bytes even if the batch is not full.
+	//
+	// Optional. Default: 1s
+	FlushInterval time.Duration
+}
+
+// AsyncWriter writes lines to an underlying writer from a separate goroutine, so slow
+// writers do not add latency to requests. Lines are queued and written in batches.
+type AsyncWriter struct {
+	w       io.Writer
+	block   bool
+	batch   []byte
+	size    int
+	queue   chan *bytebufferpool.ByteBuffer
+	flush   chan chan error
+	closing chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	err     error
+	dropped uint64
+
+	// mu serializes the writes queueing a line against Close, so no line is queued after
+	// the goroutine drained the queue
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncWriter starts the goroutine writing to w, it is stopped by Close.
+func NewAsyncWriter(w io.Writer, cfg AsyncConfig) *AsyncWriter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultAsyncQueueSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultAsyncBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultAsyncFlushInterval
+	}
+	a := &AsyncWriter{
+		w:       w,
+		block:   cfg.Block,
+		batch:   make([]byte, 0, cfg.BatchSize),
+		size:    cfg.BatchSize,
+		queue:   make(chan *bytebufferpool.ByteBuffer, cfg.QueueSize),
+		flush:   make(chan chan error),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.run(cfg.FlushInterval)
+	return a
+}
+
+// Write queues a copy of p. With a full queue the line is dropped unless Block is set.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return 0, ErrAsyncWriterClosed
+	}
+	b := asyncBufferPool.Get()
+	_, _ = b.Write(p)
+	if a.block {
+		a.queue <- b
+		return len(p), nil
+	}
+	select {
+	case a.queue <- b:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+		asyncBufferPool.Put(b)
+	}
+	return len(p), nil
+}
+
+// Flush writes the lines queued before the call and returns the first error of the underlying
+// writer since the last Flush.
+func (a *AsyncWriter) Flush() error {
+	reply := make(chan error, 1)
+	select {
+	case a.flush <- reply:
+		return <-reply
+	case <-a.done:
+		return nil
+	}
+}
+
+// Close flushes the queued lines and stops the goroutine, later writes fail. Blocked writes
+// are completed first.
+func (a *AsyncWriter) Close() (err error) {
+	a.once.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		a.mu.Unlock()
+		close(a.closing)
+		<-a.done
+		err = a.err
+	})
+	<-a.done
+	return err
+}
+
+// Dropped returns the number of lines dropped because the queue was full
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+func (a *AsyncWriter) run(interval time.Duration) {
+	defer close(a.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case b := <-a.queue:
+			a.add(b)
+		case <-ticker.C:
+			a.write()
+		case reply := <-a.flush:
+			a.drain()
+			a.write()
+			reply <- a.err
+			a.err = nil
+		case <-a.closing:
+			a.drain()
+			a.write()
+			return
+		}
+	}
+}
+
+// add appends a line to the batch and writes the batch once it is full
+func (a *AsyncWriter) add(b *bytebufferpool.ByteBuffer) {
+	a.batch = append(a.batch, b.B...)
+	asyncBufferPool.Put(b)
+	if len(a.batch) >= a.size {
+		a.write()
+	}
+}
+
+// drain adds the queued lines to the batch without waiting for new ones
+func (a *AsyncWriter) drain() {
+	for {
+		select {
+		case b := <-a.queue:
+			a.add(b)
+		default:
+			return
+		}
+	}
+}
+
+func (a *AsyncWriter) write() {
+	if len(a.batch) == 0 {
+		return
+	}
+	if _, err := a.w.Write(a.batch); err != nil && a.err == nil {
+		a.err = err
+	}
+	a.batch = a.batch[:0]
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// syncBuffer counts the writes it receives
+type syncBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	err    error
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func TestWithOutput(t *testing.T) {
+	var out bytes.Buffer
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${status} ${path}"),
+		WithOutput(&out),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+			t.Fatal("log function must not be used with WithOutput")
+		}),
+	))
+	engine.GET("/*any", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/100%25", nil)
+	ut.PerformRequest(engine, "GET", "/b", nil)
+	assert.DeepEqual(t, "200 /100%\n200 /b\n", out.String())
+}
+
+func TestAsyncWriter(t *testing.T) {
+	var out syncBuffer
+	w := NewAsyncWriter(&out, AsyncConfig{BatchSize: 1 << 20, FlushInterval: time.Hour})
+	for i := 0; i < 10; i++ {
+		_, err := w.Write([]byte("line\n"))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Flush())
+	assert.DeepEqual(t, strings.Repeat("line\n", 10), out.String())
+	// batched into a single write
+	assert.DeepEqual(t, 1, out.writes)
+
+	assert.Nil(t, w.Close())
+	_, err := w.Write([]byte("late\n"))
+	assert.DeepEqual(t, ErrAsyncWriterClosed, err)
+	assert.Nil(t, w.Flush())
+	assert.Nil(t, w.Close())
+}
+
+func TestAsyncWriterBatchSize(t *testing.T) {
+	var out syncBuffer
+	w := NewAsyncWriter(&out, AsyncConfig{BatchSize: 10, FlushInterval: time.Hour})
+	defer w.Close()
+	_, _ = w.Write([]byte("0123456789"))
+	for i := 0; i < 100 && out.String() == ""; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.DeepEqual(t, "0123456789", out.String())
+}
+
+func TestAsyncWriterInterval(t *testing.T) {
+	var out syncBuffer
+	w := NewAsyncWriter(&out, AsyncConfig{FlushInterval: 10 * time.Millisecond})
+	defer w.Close()
+	_, _ = w.Write([]byte("line\n"))
+	for i := 0; i < 100 && out.String() == ""; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.DeepEqual(t, "line\n", out.String())
+}
+
+// blockingWriter blocks the goroutine of the AsyncWriter until release is closed
+type blockingWriter struct {
+	syncBuffer
+	release chan struct{}
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	<-b.release
+	return b.syncBuffer.Write(p)
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(out, AsyncConfig{QueueSize: 2, BatchSize: 1})
+	for i := 0; i < 10; i++ {
+		_, err := w.Write([]byte("x"))
+		assert.Nil(t, err)
+	}
+	// one line is held by the blocked goroutine, two are queued
+	assert.True(t, w.Dropped() >= 7)
+	close(out.release)
+	assert.Nil(t, w.Close())
+	assert.DeepEqual(t, uint64(10), w.Dropped()+uint64(len(out.String())))
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(out, AsyncConfig{QueueSize: 1, BatchSize: 1, Block: true})
+	written := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			_, _ = w.Write([]byte("x"))
+		}
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("writes must block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(out.release)
+	<-written
+	assert.Nil(t, w.Close())
+	assert.DeepEqual(t, uint64(0), w.Dropped())
+	assert.DeepEqual(t, "xxxxx", out.String())
+}
+
+func TestAsyncWriterConcurrentClose(t *testing.T) {
+	for _, block := range []bool{false, true} {
+		var out syncBuffer
+		w := NewAsyncWriter(&out, AsyncConfig{QueueSize: 4, Block: block})
+		var (
+			wg    sync.WaitGroup
+			acked uint64
+		)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if _, err := w.Write([]byte("x")); err != nil {
+						return
+					}
+					atomic.AddUint64(&acked, 1)
+				}
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		assert.Nil(t, w.Close())
+		wg.Wait()
+		// every acknowledged line is written or counted as dropped
+		assert.DeepEqual(t, atomic.LoadUint64(&acked), uint64(len(out.String()))+w.Dropped())
+	}
+}
+
+func TestAsyncWriterError(t *testing.T) {
+	out := &syncBuffer{err: errors.New("disk full")}
+	w := NewAsyncWriter(out, AsyncConfig{})
+	_, _ = w.Write([]byte("line\n"))
+	assert.DeepEqual(t, out.err, w.Flush())
+	assert.Nil(t, w.Flush())
+	assert.Nil(t, w.Close())
+}
+
+func TestNewWithStopFlushes(t *testing.T) {
+	var out syncBuffer
+	w := NewAsyncWriter(&out, AsyncConfig{FlushInterval: time.Hour})
+	defer w.Close()
+	mw, stop := NewWithStop(WithFormat("${status}"), WithOutput(w))
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(mw)
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/", nil)
+	stop(context.Background())
+	assert.DeepEqual(t, "200\n", out.String())
+}
+
+func TestWithSink(t *testing.T) {
+	var line string
+	var level hlog.Level
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${status} ${path}"),
+		WithLevelPolicy(StatusLevelPolicy(0)),
+		WithSink(func(ctx context.Context, l hlog.Level, b []byte) {
+			level, line = l, string(b)
+		}),
+	))
+	engine.GET("/*any", func(ctx context.Context, c *app.RequestContext) {
+		c.Status(404)
+	})
+	ut.PerformRequest(engine, "GET", "/%25s%25n", nil)
+	assert.DeepEqual(t, "404 /%s%n", line)
+	assert.DeepEqual(t, hlog.LevelWarn, level)
+}
+
+func TestAccessLogFuncEscapesPercent(t *testing.T) {
+	var (
+		line   string
+		format string
+		args   int
+	)
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${status} ${path}"),
+		WithAccessLogFunc(func(ctx context.Context, f string, v ...interface{}) {
+			format, args = f, len(v)
+			line = fmt.Sprintf(f, v...)
+		}),
+	))
+	engine.GET("/*any", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/%25s%25n%25%25", nil)
+	assert.DeepEqual(t, "200 /%s%n%%", line)
+	// the escaped line is the format, without arguments
+	assert.DeepEqual(t, "200 /%%s%%n%%%%", format)
+	assert.DeepEqual(t, 0, args)
+
+	ut.PerformRequest(engine, "GET", "/plain", nil)
+	assert.DeepEqual(t, "200 /plain", line)
+	assert.DeepEqual(t, "200 /plain", format)
+
+	// without WithAccessLogFunc the line is logged by hlog as is
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
+	engine = route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithFormat("${status} ${path}")))
+	engine.GET("/*any", func(ctx context.Context, c *app.RequestContext) {})
+	ut.PerformRequest(engine, "GET", "/100%25", nil)
+	assert.Assert(t, strings.HasSuffix(buf.String(), "200 /100%\n"), buf.String())
+}
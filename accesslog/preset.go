@@ -0,0 +1,242 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// ECSVersion is the version of the Elastic Common Schema written by WithECSFields
+const ECSVersion = "8.11.0"
+
+// presetField is a key of a nested JSON preset, the value is rendered by tag or by logFunc
+type presetField struct {
+	path      []string
+	tag       string
+	logFunc   LogFunc
+	numeric   bool
+	omitEmpty bool
+}
+
+// newPresetEncoder returns the encoder of a nested JSON preset, the preset fields must be
+// grouped by object
+func newPresetEncoder(preset []presetField) *encoder {
+	return &encoder{
+		build: func(names []string, tagFunctions map[string]LogFunc) ([]field, error) {
+			return buildPresetFields(preset, tagFunctions)
+		},
+		write: writeNestedJSON,
+	}
+}
+
+// presetTags returns the tags of a preset, see options.usesTag
+func presetTags(preset []presetField) []string {
+	tags := make([]string, 0, len(preset))
+	for _, p := range preset {
+		if p.tag != "" {
+			tags = append(tags, p.tag)
+		}
+	}
+	return tags
+}
+
+func buildPresetFields(preset []presetField, tagFunctions map[string]LogFunc) ([]field, error) {
+	fields := make([]field, 0, len(preset))
+	for _, p := range preset {
+		logFunc := p.logFunc
+		if logFunc == nil {
+			var ok bool
+			if logFunc, ok = tagFunctions[p.tag]; !ok {
+				return nil, errors.New("Unknown tag \"" + p.tag + "\" in preset")
+			}
+		}
+		fields = append(fields, field{
+			key:       p.path[len(p.path)-1],
+			path:      p.path,
+			logFunc:   logFunc,
+			numeric:   p.numeric,
+			omitEmpty: p.omitEmpty,
+		})
+	}
+	return fields, nil
+}
+
+// writeNestedJSON renders the fields as a JSON object with nested objects for the paths of
+// the fields. Empty or "-" values of omitEmpty fields are left out, and so are objects
+// without values.
+func writeNestedJSON(output Buffer, fields []field, c *app.RequestContext, data *Data) error {
+	value := bytebufferpool.Get()
+	defer bytebufferpool.Put(value)
+
+	var open []string
+	// empty tells for the root and each open object whether it has no member yet
+	empty := make([]bool, 1, 4)
+	empty[0] = true
+	writeKey := func(key string) {
+		if !empty[len(empty)-1] {
+			_ = output.WriteByte(',')
+		}
+		empty[len(empty)-1] = false
+		output.Set(appendJSONString(output.Bytes(), unsafeBytes(key)))
+		_ = output.WriteByte(':')
+	}
+
+	_ = output.WriteByte('{')
+	for _, f := range fields {
+		value.Reset()
+		if _, err := f.logFunc(value, c, data, f.param); err != nil {
+			return err
+		}
+		if f.omitEmpty && (len(value.B) == 0 || (len(value.B) == 1 && value.B[0] == '-')) {
+			continue
+		}
+
+		parents := f.path[:len(f.path)-1]
+		depth := 0
+		for depth < len(open) && depth < len(parents) && open[depth] == parents[depth] {
+			depth++
+		}
+		for len(open) > depth {
+			_ = output.WriteByte('}')
+			open = open[:len(open)-1]
+			empty = empty[:len(empty)-1]
+		}
+		for _, key := range parents[depth:] {
+			writeKey(key)
+			_ = output.WriteByte('{')
+			open = append(open, key)
+			empty = append(empty, true)
+		}
+
+		writeKey(f.key)
+		if f.numeric && isJSONNumber(value.B) {
+			_, _ = output.Write(value.B)
+		} else {
+			output.Set(appendJSONString(output.Bytes(), value.B))
+		}
+	}
+	for range open {
+		_ = output.WriteByte('}')
+	}
+	_ = output.WriteByte('}')
+	return nil
+}
+
+// presetLatencyNanos writes the latency in nanoseconds regardless of WithLatencyUnit
+func presetLatencyNanos(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+	return appendInt(output, int(data.Stop.Sub(data.Start)))
+}
+
+// presetClientIP writes the IP of the connection peer for the fields of IP type. A hashed or
+// suppressed IP is not a valid IP, the field is left out then.
+func presetClientIP(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+	an := data.options().ipAnonymizer
+	if an != nil && an.mode != IPTruncate {
+		return 0, nil
+	}
+	return an.writeIP(output, remoteIP(c))
+}
+
+var ecsPreset = []presetField{
+	{path: []string{"@timestamp"}, tag: TagStartTime},
+	{path: []string{"ecs", "version"}, logFunc: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return output.WriteString(ECSVersion)
+	}},
+	{path: []string{"event", "duration"}, tag: TagLatency, logFunc: presetLatencyNanos, numeric: true},
+	{path: []string{"client", "ip"}, tag: TagRemoteAddr, logFunc: presetClientIP, omitEmpty: true},
+	{path: []string{"http", "version"}, logFunc: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return output.WriteString(strings.TrimPrefix(c.Request.Header.GetProtocol(), "HTTP/"))
+	}},
+	{path: []string{"http", "request", "id"}, tag: TagRequestID, omitEmpty: true},
+	{path: []string{"http", "request", "method"}, tag: TagMethod},
+	{path: []string{"http", "request", "referrer"}, tag: TagReferer, omitEmpty: true},
+	{path: []string{"http", "request", "body", "bytes"}, tag: TagBytesReceived, numeric: true},
+	{path: []string{"http", "response", "status_code"}, tag: TagStatus, numeric: true},
+	{path: []string{"http", "response", "body", "bytes"}, tag: TagBytesSent, numeric: true},
+	{path: []string{"url", "original"}, tag: TagURL},
+	{path: []string{"url", "path"}, tag: TagPath},
+	{path: []string{"url", "query"}, tag: TagQueryStringParams, omitEmpty: true},
+	{path: []string{"user_agent", "original"}, tag: TagUA, omitEmpty: true},
+	{path: []string{"trace", "id"}, tag: TagTraceID, omitEmpty: true},
+	{path: []string{"span", "id"}, tag: TagSpanID, omitEmpty: true},
+	{path: []string{"error", "message"}, tag: TagError, omitEmpty: true},
+}
+
+// gcpSeverities map the levels to the severities of Cloud Logging
+var gcpSeverities = map[hlog.Level]string{
+	hlog.LevelTrace:  "DEBUG",
+	hlog.LevelDebug:  "DEBUG",
+	hlog.LevelInfo:   "INFO",
+	hlog.LevelNotice: "NOTICE",
+	hlog.LevelWarn:   "WARNING",
+	hlog.LevelError:  "ERROR",
+	hlog.LevelFatal:  "CRITICAL",
+}
+
+// gcpPreset returns the fields of a Cloud Logging structured log entry, the trace fields
+// are only written with a projectID
+func gcpPreset(projectID string) []presetField {
+	preset := []presetField{
+		{path: []string{"severity"}, logFunc: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+			level := hlog.LevelInfo
+			if policy := data.options().levelPolicy; policy != nil {
+				level = policy(c, data.Stop.Sub(data.Start))
+			}
+			return output.WriteString(gcpSeverities[level])
+		}},
+		{path: []string{"time"}, tag: TagStartTime},
+		{path: []string{"httpRequest", "requestMethod"}, tag: TagMethod},
+		{path: []string{"httpRequest", "requestUrl"}, tag: TagURL},
+		// int64 values are strings in the JSON mapping of protocol buffers
+		{path: []string{"httpRequest", "requestSize"}, tag: TagBytesReceived},
+		{path: []string{"httpRequest", "status"}, tag: TagStatus, numeric: true},
+		{path: []string{"httpRequest", "responseSize"}, tag: TagBytesSent},
+		{path: []string{"httpRequest", "userAgent"}, tag: TagUA, omitEmpty: true},
+		{path: []string{"httpRequest", "remoteIp"}, tag: TagRemoteAddr, logFunc: presetClientIP, omitEmpty: true},
+		{path: []string{"httpRequest", "referer"}, tag: TagReferer, omitEmpty: true},
+		{path: []string{"httpRequest", "latency"}, tag: TagLatency, logFunc: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+			n, _ := writeLatency(output, data.Stop.Sub(data.Start), "s")
+			_ = output.WriteByte('s')
+			return n + 1, nil
+		}},
+		{path: []string{"httpRequest", "protocol"}, logFunc: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+			return output.WriteString(c.Request.Header.GetProtocol())
+		}},
+	}
+	if projectID == "" {
+		return preset
+	}
+	prefix := "projects/" + projectID + "/traces/"
+	traceID := Tags[TagTraceID]
+	return append(preset,
+		presetField{path: []string{"logging.googleapis.com/trace"}, tag: TagTraceID, omitEmpty: true, logFunc: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+			old := output.Len()
+			_, _ = output.WriteString(prefix)
+			if n, _ := traceID(output, c, data, extraParam); n == 0 {
+				output.Set(output.Bytes()[:old])
+				return 0, nil
+			}
+			return output.Len() - old, nil
+		}},
+		presetField{path: []string{"logging.googleapis.com/spanId"}, tag: TagSpanID, omitEmpty: true},
+	)
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/errors"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// renderPreset renders the request of newCLFContext with a preset option
+func renderPreset(t *testing.T, opt Option, decorate bool) []byte {
+	t.Helper()
+	c, data := newCLFContext()
+	data.Stop = data.Start.Add(123456789 * time.Nanosecond)
+	if decorate {
+		c.Request.Header.Set("User-Agent", "Mozilla/5.0 \"test\"")
+		c.Request.Header.Set("Referer", "http://www.example.com/start.html")
+		c.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		c.Request.SetBodyString("name=frank")
+		c.Response.SetStatusCode(500)
+		_ = c.Error(errors.NewPrivate("database unavailable"))
+		data.RequestID = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+	}
+	cfg := newOptions(opt, WithTimeZoneLocation(time.UTC))
+	data.cfg = cfg
+	fields, err := cfg.encoder.build(cfg.fields, Tags)
+	assert.Nil(t, err)
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	assert.Nil(t, cfg.encoder.write(buf, fields, c, data))
+	assert.True(t, json.Valid(buf.B))
+	return append([]byte(nil), buf.B...)
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.Nil(t, os.WriteFile(path, append(got, '\n'), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, string(want), string(got)+"\n")
+}
+
+func TestECSFieldsGolden(t *testing.T) {
+	assertGolden(t, "ecs_minimal.golden", renderPreset(t, WithECSFields(), false))
+	assertGolden(t, "ecs_full.golden", renderPreset(t, WithECSFields(), true))
+}
+
+func TestGCPFieldsGolden(t *testing.T) {
+	assertGolden(t, "gcp_minimal.golden", renderPreset(t, WithGCPFields(""), false))
+	assertGolden(t, "gcp_full.golden", renderPreset(t, WithGCPFields("my-project"), true))
+}
+
+func TestGCPSeverity(t *testing.T) {
+	var entry struct {
+		Severity    string `json:"severity"`
+		HTTPRequest struct {
+			Latency string `json:"latency"`
+		} `json:"httpRequest"`
+	}
+	line := renderPreset(t, func(o *options) {
+		WithGCPFields("")(o)
+		WithLevelPolicy(StatusLevelPolicy(0))(o)
+	}, true)
+	assert.Nil(t, json.Unmarshal(line, &entry))
+	assert.DeepEqual(t, "ERROR", entry.Severity)
+	assert.DeepEqual(t, "0.123456s", entry.HTTPRequest.Latency)
+}
+
+func TestPresetClientIPAnonymized(t *testing.T) {
+	for _, mode := range []IPAnonymizationMode{IPTruncate, IPHash, IPSuppress} {
+		for _, opt := range []Option{WithECSFields(), WithGCPFields("")} {
+			c, data := newCLFContext()
+			cfg := newOptions(opt, WithIPAnonymization(IPAnonymization{Mode: mode}))
+			data.cfg = cfg
+			fields, err := cfg.encoder.build(cfg.fields, Tags)
+			assert.Nil(t, err)
+			buf := bytebufferpool.Get()
+			assert.Nil(t, cfg.encoder.write(buf, fields, c, data))
+
+			var entry struct {
+				Client struct {
+					IP *string `json:"ip"`
+				} `json:"client"`
+				HTTPRequest struct {
+					RemoteIP *string `json:"remoteIp"`
+				} `json:"httpRequest"`
+			}
+			assert.Nil(t, json.Unmarshal(buf.B, &entry))
+			bytebufferpool.Put(buf)
+			ip := entry.Client.IP
+			if ip == nil {
+				ip = entry.HTTPRequest.RemoteIP
+			}
+			if mode == IPTruncate {
+				assert.True(t, ip != nil)
+				assert.DeepEqual(t, "127.0.0.0", *ip)
+			} else {
+				assert.True(t, ip == nil)
+			}
+		}
+	}
+}
+
+func TestECSFields(t *testing.T) {
+	var line []byte
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(WithECSFields(), WithSink(func(ctx context.Context, level hlog.Level, b []byte) {
+		line = append(line[:0], b...)
+	})))
+	engine.GET("/ping", func(ctx context.Context, c *app.RequestContext) {
+		time.Sleep(time.Millisecond)
+	})
+	ut.PerformRequest(engine, "GET", "/ping?a=1", nil)
+
+	var entry struct {
+		Event struct {
+			Duration int64 `json:"duration"`
+		} `json:"event"`
+		HTTP struct {
+			Request struct {
+				Method string `json:"method"`
+			} `json:"request"`
+			Response struct {
+				StatusCode int `json:"status_code"`
+			} `json:"response"`
+		} `json:"http"`
+		URL struct {
+			Path  string `json:"path"`
+			Query string `json:"query"`
+		} `json:"url"`
+	}
+	assert.Nil(t, json.Unmarshal(line, &entry))
+	assert.True(t, entry.Event.Duration >= int64(time.Millisecond))
+	assert.DeepEqual(t, "GET", entry.HTTP.Request.Method)
+	assert.DeepEqual(t, 200, entry.HTTP.Response.StatusCode)
+	assert.DeepEqual(t, "/ping", entry.URL.Path)
+	assert.DeepEqual(t, "a=1", entry.URL.Query)
+}
@@ -0,0 +1,394 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultRedactionMask = "***"
+	redactionHashKeySize = 32
+)
+
+// Redaction defines the values that are masked in the tags that log headers, query
+// arguments, form values and bodies.
+type Redaction struct {
+	// Headers are the names of the request and response headers to mask, case-insensitive.
+	// Masking "Cookie" also masks ${cookie:*}.
+	Headers []string
+
+	// QueryParams are the names of the query arguments to mask in ${queryParams},
+	// ${query:*}, ${url} and ${requestLine}.
+	QueryParams []string
+
+	// BodyFields are the form fields and JSON keys to mask in ${body}, ${resBody} and ${form:*}.
+	// A plain name like "password" matches the key at any depth of a JSON document,
+	// a dotted path like "user.password" only matches from the root. Arrays are transparent.
+	// Bodies that are not valid JSON or form documents are masked as a whole.
+	BodyFields []string
+
+	// Patterns are masked wherever they match in the values of the tags above,
+	// e.g. regexp.MustCompile(`\b\d{13,16}\b`) for card numbers.
+	Patterns []*regexp.Regexp
+
+	// Mask replaces the redacted values.
+	//
+	// Optional. Default: ***
+	Mask string
+
+	// Hash replaces the redacted values with a truncated HMAC-SHA256 of the value keyed by
+	// HashKey instead of Mask, so equal values can still be correlated.
+	//
+	// Optional. Default: false
+	Hash bool
+
+	// HashKey is the key of Hash. An empty key would let a dictionary attack recover
+	// low-entropy values like passwords, so a random key is generated instead. Values then
+	// only correlate within the middleware instance.
+	//
+	// Optional. Default: a random key
+	HashKey []byte
+}
+
+// redactor masks values as configured by Redaction, a nil *redactor redacts nothing
+type redactor struct {
+	headers    []string
+	query      []string
+	fields     map[string]bool
+	paths      map[string]bool
+	patterns   []*regexp.Regexp
+	mask       []byte
+	hash       bool
+	hashKey    []byte
+	hasCookies bool
+}
+
+func newRedactor(r Redaction) *redactor {
+	rd := &redactor{
+		headers:  r.Headers,
+		query:    r.QueryParams,
+		fields:   make(map[string]bool),
+		paths:    make(map[string]bool),
+		patterns: r.Patterns,
+		mask:     []byte(r.Mask),
+		hash:     r.Hash,
+		hashKey:  r.HashKey,
+	}
+	if r.Mask == "" {
+		rd.mask = []byte(defaultRedactionMask)
+	}
+	if r.Hash && len(r.HashKey) == 0 {
+		rd.hashKey = make([]byte, redactionHashKeySize)
+		if _, err := rand.Read(rd.hashKey); err != nil {
+			panic("Cannot generate redaction hash key: " + err.Error())
+		}
+	}
+	for _, f := range r.BodyFields {
+		if strings.Contains(f, ".") {
+			rd.paths[f] = true
+		} else {
+			rd.fields[f] = true
+		}
+	}
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, "Cookie") {
+			rd.hasCookies = true
+		}
+	}
+	return rd
+}
+
+func (r *redactor) header(name []byte) bool {
+	if r == nil {
+		return false
+	}
+	for _, h := range r.headers {
+		if bytes.EqualFold(unsafeBytes(h), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) queryParam(name []byte) bool {
+	if r == nil {
+		return false
+	}
+	if bytes.IndexByte(name, '%') >= 0 || bytes.IndexByte(name, '+') >= 0 {
+		if unescaped, err := url.QueryUnescape(string(name)); err == nil {
+			name = unsafeBytes(unescaped)
+		}
+	}
+	for _, q := range r.query {
+		if unsafeString(name) == q {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) formField(name []byte) bool {
+	if r == nil {
+		return false
+	}
+	if bytes.IndexByte(name, '%') >= 0 || bytes.IndexByte(name, '+') >= 0 {
+		if unescaped, err := url.QueryUnescape(string(name)); err == nil {
+			return r.fields[unescaped] || r.paths[unescaped]
+		}
+	}
+	return r.fields[unsafeString(name)] || r.paths[unsafeString(name)]
+}
+
+// appendMask appends the replacement of value
+func (r *redactor) appendMask(dst, value []byte) []byte {
+	if !r.hash {
+		return append(dst, r.mask...)
+	}
+	mac := hmac.New(sha256.New, r.hashKey)
+	_, _ = mac.Write(value)
+	var sum [sha256.Size]byte
+	dst = append(dst, "sha256:"...)
+	for _, b := range mac.Sum(sum[:0])[:8] {
+		dst = append(dst, hex[b>>4], hex[b&0xf])
+	}
+	return dst
+}
+
+// writeValue writes value, or its mask if redact is true
+func (r *redactor) writeValue(output Buffer, value []byte, redact bool) (int, error) {
+	if r == nil {
+		return output.Write(value)
+	}
+	old := output.Len()
+	if redact && len(value) > 0 {
+		output.Set(r.appendMask(output.Bytes(), value))
+	} else {
+		_, _ = output.Write(value)
+	}
+	r.redactPatterns(output, old)
+	return output.Len() - old, nil
+}
+
+// redactPatterns masks the patterns in everything written to output after offset
+func (r *redactor) redactPatterns(output Buffer, offset int) {
+	if r == nil || len(r.patterns) == 0 || output.Len() == offset {
+		return
+	}
+	b := output.Bytes()
+	value := b[offset:]
+	changed := false
+	for _, p := range r.patterns {
+		if !p.Match(value) {
+			continue
+		}
+		value = p.ReplaceAllFunc(value, func(m []byte) []byte {
+			return r.appendMask(nil, m)
+		})
+		changed = true
+	}
+	if changed {
+		output.Set(append(b[:offset], value...))
+	}
+}
+
+// appendPairs appends the key=value pairs of a query string or form body replacing the values
+// of the keys matched by redact, the original encoding of the other pairs is kept
+func (r *redactor) appendPairs(dst, src []byte, redact func(key []byte) bool) []byte {
+	for i := 0; len(src) > 0; i++ {
+		pair := src
+		if n := bytes.IndexByte(src, '&'); n >= 0 {
+			pair, src = src[:n], src[n+1:]
+		} else {
+			src = nil
+		}
+		if i > 0 {
+			dst = append(dst, '&')
+		}
+		n := bytes.IndexByte(pair, '=')
+		if n < 0 || !redact(pair[:n]) {
+			dst = append(dst, pair...)
+			continue
+		}
+		dst = append(dst, pair[:n+1]...)
+		dst = r.appendMask(dst, pair[n+1:])
+	}
+	return dst
+}
+
+// writeURI writes a request URI replacing the values of redacted query arguments
+func (r *redactor) writeURI(output Buffer, uri []byte) (int, error) {
+	if r == nil {
+		return output.Write(uri)
+	}
+	old := output.Len()
+	n := bytes.IndexByte(uri, '?')
+	if n < 0 || len(r.query) == 0 {
+		_, _ = output.Write(uri)
+	} else {
+		dst := append(output.Bytes(), uri[:n+1]...)
+		output.Set(r.appendPairs(dst, uri[n+1:], r.queryParam))
+	}
+	r.redactPatterns(output, old)
+	return output.Len() - old, nil
+}
+
+// writeBody writes a JSON or form body replacing the values of redacted fields, other bodies are
+// only checked for patterns. With body fields configured, bodies that cannot be parsed are
+// replaced by the mask, so a malformed document never leaks the fields.
+func (r *redactor) writeBody(output Buffer, contentType, body []byte) (int, error) {
+	if r == nil {
+		return output.Write(body)
+	}
+	old := output.Len()
+	switch {
+	case !r.hasBodyFields() || len(body) == 0:
+		_, _ = output.Write(body)
+	case bytes.Contains(contentType, []byte("json")):
+		if dst, err := r.appendJSON(output.Bytes(), body); err == nil {
+			output.Set(dst)
+		} else {
+			output.Set(r.appendMask(output.Bytes(), body))
+		}
+	case bytes.HasPrefix(contentType, []byte("application/x-www-form-urlencoded")):
+		output.Set(r.appendPairs(output.Bytes(), body, r.formField))
+	default:
+		output.Set(r.appendMask(output.Bytes(), body))
+	}
+	r.redactPatterns(output, old)
+	return output.Len() - old, nil
+}
+
+// hasBodyFields reports whether body fields are redacted
+func (r *redactor) hasBodyFields() bool {
+	return r != nil && (len(r.fields) > 0 || len(r.paths) > 0)
+}
+
+// appendJSON re-encodes the JSON document src in compact form replacing the values of redacted
+// keys, dst is returned unchanged with an error if src is not valid JSON
+func (r *redactor) appendJSON(dst, src []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	type container struct {
+		object bool
+		n      int
+	}
+	var (
+		stack  []container
+		keys   []string
+		out    = dst
+		redact bool
+	)
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if len(stack) > 0 || redact {
+				return dst, io.ErrUnexpectedEOF
+			}
+			return out, nil
+		}
+		if err != nil {
+			return dst, err
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			if d == '}' {
+				keys = keys[:len(keys)-1]
+			}
+			out = append(out, byte(d))
+			continue
+		}
+
+		// write separators, in objects even elements are keys and odd elements are values
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			isKey := top.object && top.n%2 == 0
+			switch {
+			case top.object && !isKey:
+				out = append(out, ':')
+			case top.n > 0:
+				out = append(out, ',')
+			}
+			top.n++
+			if isKey {
+				key, _ := tok.(string)
+				keys[len(keys)-1] = key
+				redact = r.fields[key] || (len(r.paths) > 0 && r.paths[strings.Join(keys, ".")])
+				out = appendJSONString(out, unsafeBytes(key))
+				continue
+			}
+		}
+
+		if redact {
+			redact = false
+			var value []byte
+			if d, ok := tok.(json.Delim); ok {
+				// skip the whole container and mask its raw text
+				for depth := 1; depth > 0; {
+					if tok, err = dec.Token(); err != nil {
+						return dst, err
+					}
+					if d, ok = tok.(json.Delim); ok {
+						if d == '{' || d == '[' {
+							depth++
+						} else {
+							depth--
+						}
+					}
+				}
+				value = bytes.TrimLeft(src[offset:dec.InputOffset()], " \t\r\n:")
+			} else if v, ok := tok.(string); ok {
+				value = unsafeBytes(v)
+			} else {
+				value = bytes.TrimLeft(src[offset:dec.InputOffset()], " \t\r\n:")
+			}
+			out = append(out, '"')
+			out = r.appendMask(out, value)
+			out = append(out, '"')
+			continue
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			stack = append(stack, container{object: v == '{'})
+			if v == '{' {
+				keys = append(keys, "")
+			}
+			out = append(out, byte(v))
+		case string:
+			out = appendJSONString(out, unsafeBytes(v))
+		case json.Number:
+			out = append(out, v...)
+		case bool:
+			out = strconv.AppendBool(out, v)
+		case nil:
+			out = append(out, "null"...)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestRedactionHeadersAndQuery(t *testing.T) {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${reqHeader:Authorization}|${cookie:session}|${query:token}|${queryParams}|${url}|${reqHeaders}"),
+		WithRedaction(Redaction{
+			Headers:     []string{"authorization", "Cookie"},
+			QueryParams: []string{"token"},
+		}),
+	))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	request := ut.PerformRequest(engine, "GET", "/?token=abc&page=2", nil,
+		ut.Header{Key: "Authorization", Value: "Bearer secret"},
+		ut.Header{Key: "Cookie", Value: "session=s3cr3t"})
+	assert.DeepEqual(t, 200, request.Result().StatusCode())
+
+	line := buf.String()
+	assert.False(t, strings.Contains(line, "secret"))
+	assert.False(t, strings.Contains(line, "s3cr3t"))
+	assert.False(t, strings.Contains(line, "abc"))
+	assert.True(t, strings.Contains(line, "***|***|***|token=***&page=2|/?token=***&page=2|"))
+	assert.True(t, strings.Contains(line, "Authorization=***"))
+	assert.True(t, strings.Contains(line, "Cookie=***"))
+}
+
+func TestRedactionBody(t *testing.T) {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stderr)
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${body}|${resBody}|${form:password}"),
+		WithRedaction(Redaction{
+			BodyFields: []string{"password", "card.number"},
+			Patterns:   []*regexp.Regexp{regexp.MustCompile(`\b\d{16}\b`)},
+			Mask:       "[REDACTED]",
+		}),
+	))
+	engine.POST("/form", func(ctx context.Context, c *app.RequestContext) {
+		c.String(200, "card 4111111111111111")
+	})
+	engine.POST("/json", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(200, map[string]interface{}{"card": map[string]string{"number": "4111111111111111"}})
+	})
+
+	request := ut.PerformRequest(engine, "POST", "/form",
+		&ut.Body{Body: strings.NewReader("user=bob&password=hunter2"), Len: -1},
+		ut.Header{Key: "Content-Type", Value: "application/x-www-form-urlencoded"})
+	assert.DeepEqual(t, 200, request.Result().StatusCode())
+	assert.True(t, strings.HasSuffix(buf.String(),
+		"user=bob&password=[REDACTED]|[REDACTED]|[REDACTED]\n"))
+
+	buf.Reset()
+	request = ut.PerformRequest(engine, "POST", "/json",
+		&ut.Body{Body: strings.NewReader(`{"user": "bob", "password": {"old": "a", "new": "b"}, "number": 1}`), Len: -1},
+		ut.Header{Key: "Content-Type", Value: "application/json"})
+	assert.DeepEqual(t, 200, request.Result().StatusCode())
+	assert.True(t, strings.HasSuffix(buf.String(),
+		`{"user":"bob","password":"[REDACTED]","number":1}|{"card":{"number":"[REDACTED]"}}|`+"\n"))
+}
+
+func TestRedactionBodyFailsClosed(t *testing.T) {
+	rd := newRedactor(Redaction{BodyFields: []string{"password"}})
+	for contentType, body := range map[string]string{
+		"application/json":                `{"user":"bob","password":"hunter2",}`,
+		"application/json; charset=utf-8": `{"user":"bob","password":"hunter2"`,
+		"multipart/form-data; boundary=x": "--x\r\nContent-Disposition: form-data; name=\"password\"\r\n\r\nhunter2\r\n--x--",
+		"text/plain":                      "password=hunter2",
+	} {
+		buf := bytebufferpool.Get()
+		_, _ = rd.writeBody(buf, []byte(contentType), []byte(body))
+		assert.DeepEqual(t, "***", buf.String())
+		bytebufferpool.Put(buf)
+	}
+
+	// without body fields only the patterns apply
+	rd = newRedactor(Redaction{Headers: []string{"Authorization"}})
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	_, _ = rd.writeBody(buf, []byte("application/json"), []byte(`{"password":`))
+	assert.DeepEqual(t, `{"password":`, buf.String())
+}
+
+func TestRedactionJSON(t *testing.T) {
+	rd := newRedactor(Redaction{BodyFields: []string{"secret", "a.b"}})
+	for src, want := range map[string]string{
+		`{}`:                                  `{}`,
+		`[]`:                                  `[]`,
+		`"secret"`:                            `"secret"`,
+		`{"secret":1}`:                        `{"secret":"***"}`,
+		`{"x":[{"secret":[1,2]},{"y":true}]}`: `{"x":[{"secret":"***"},{"y":true}]}`,
+		`{"a":{"b":null,"c":1.5},"b":"keep"}`: `{"a":{"b":"***","c":1.5},"b":"keep"}`,
+		`{"b":{"a":{"b":1}}}`:                 `{"b":{"a":{"b":1}}}`,
+		" {\"k\" : \"v\\n\" , \"l\":[ ] } ":   `{"k":"v\n","l":[]}`,
+	} {
+		out, err := rd.appendJSON(nil, []byte(src))
+		assert.Nil(t, err)
+		assert.DeepEqual(t, want, string(out))
+	}
+
+	out, err := rd.appendJSON([]byte("prefix"), []byte(`{"secret":`))
+	assert.NotNil(t, err)
+	assert.DeepEqual(t, "prefix", string(out))
+}
+
+func TestRedactionHash(t *testing.T) {
+	rd := newRedactor(Redaction{Hash: true, HashKey: []byte("key")})
+	a := string(rd.appendMask(nil, []byte("value")))
+	assert.True(t, strings.HasPrefix(a, "sha256:"))
+	assert.DeepEqual(t, len("sha256:")+16, len(a))
+	assert.DeepEqual(t, a, string(rd.appendMask(nil, []byte("value"))))
+	assert.NotEqual(t, a, string(rd.appendMask(nil, []byte("other"))))
+	assert.NotEqual(t, a, string(newRedactor(Redaction{Hash: true, HashKey: []byte("k2")}).appendMask(nil, []byte("value"))))
+}
+
+func TestRedactionHashRandomKey(t *testing.T) {
+	rd := newRedactor(Redaction{Hash: true})
+	assert.DeepEqual(t, redactionHashKeySize, len(rd.hashKey))
+	a := string(rd.appendMask(nil, []byte("hunter2")))
+	assert.DeepEqual(t, a, string(rd.appendMask(nil, []byte("hunter2"))))
+	// not the HMAC with an empty key
+	empty := &redactor{hash: true}
+	assert.NotEqual(t, a, string(empty.appendMask(nil, []byte("hunter2"))))
+	assert.NotEqual(t, a, string(newRedactor(Redaction{Hash: true}).appendMask(nil, []byte("hunter2"))))
+}
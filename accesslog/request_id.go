@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	// DefaultRequestIDHeader is the header the request ID is read from and written to
+	DefaultRequestIDHeader = "X-Request-Id"
+	// DefaultRequestIDContextKey is the context key of the request ID. It is a plain string so that
+	// the logger adapters can read it, e.g. zap.WithExtraKeys([]zap.ExtraKey{"requestID"}) with
+	// zap.WithExtraKeyAsStr().
+	DefaultRequestIDContextKey = "requestID"
+
+	maxRequestIDLen = 128
+)
+
+// RequestID defines how the request ID of ${requestID} is obtained and propagated.
+type RequestID struct {
+	// Header is read from the request and set on the response.
+	//
+	// Optional. Default: X-Request-Id
+	Header string
+
+	// Generator creates the ID of requests without one, e.g. UUIDv4 or ULID.
+	//
+	// Optional. Default: UUIDv4
+	Generator func() string
+
+	// ContextKey stores the ID in the context passed to c.Next and to the log function.
+	//
+	// Optional. Default: DefaultRequestIDContextKey
+	ContextKey interface{}
+
+	// IgnoreIncoming always generates a new ID instead of trusting the request header.
+	// Incoming IDs longer than 128 bytes or with characters outside printable ASCII are
+	// always replaced.
+	//
+	// Optional. Default: false
+	IgnoreIncoming bool
+}
+
+type requestID struct {
+	header         string
+	generator      func() string
+	key            interface{}
+	ignoreIncoming bool
+}
+
+func newRequestID(r RequestID) *requestID {
+	id := &requestID{
+		header:         r.Header,
+		generator:      r.Generator,
+		key:            r.ContextKey,
+		ignoreIncoming: r.IgnoreIncoming,
+	}
+	if id.header == "" {
+		id.header = DefaultRequestIDHeader
+	}
+	if id.generator == nil {
+		id.generator = UUIDv4
+	}
+	if id.key == nil {
+		id.key = DefaultRequestIDContextKey
+	}
+	return id
+}
+
+// handle reads or generates the ID, sets it on the response and returns the context holding it
+func (r *requestID) handle(ctx context.Context, c *app.RequestContext) (context.Context, string) {
+	var id string
+	if !r.ignoreIncoming {
+		if v := c.Request.Header.Peek(r.header); validRequestID(v) {
+			id = string(v)
+		}
+	}
+	if id == "" {
+		id = r.generator()
+	}
+	c.Response.Header.Set(r.header, id)
+	return context.WithValue(ctx, r.key, id), id
+}
+
+func validRequestID(id []byte) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, b := range id {
+		if b <= ' ' || b > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDFromContext returns the request ID stored under DefaultRequestIDContextKey
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(DefaultRequestIDContextKey).(string)
+	return id
+}
+
+// UUIDv4 returns a random UUID as defined by RFC 4122
+func UUIDv4() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+
+	var b [36]byte
+	j := 0
+	for i, v := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			b[j] = '-'
+			j++
+		}
+		b[j], b[j+1] = hex[v>>4], hex[v&0x0f]
+		j += 2
+	}
+	return string(b[:])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID returns a lexicographically sortable ID made of the current time in milliseconds
+// and 80 random bits, as defined by https://github.com/ulid/spec
+func ULID() string {
+	return ulidAt(time.Now())
+}
+
+func ulidAt(t time.Time) string {
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], uint64(t.UnixNano()/int64(time.Millisecond))<<16)
+	_, _ = rand.Read(u[6:])
+
+	// 128 bits in 26 characters of 5 bits, the first one only holds 3 bits
+	hi, lo := binary.BigEndian.Uint64(u[:8]), binary.BigEndian.Uint64(u[8:])
+	var b [26]byte
+	for i := 25; i >= 0; i-- {
+		b[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(b[:])
+}
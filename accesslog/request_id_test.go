@@ -0,0 +1,104 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestUUIDv4(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, b := UUIDv4(), UUIDv4()
+	assert.True(t, re.MatchString(a))
+	assert.NotEqual(t, a, b)
+}
+
+func TestULID(t *testing.T) {
+	re := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	id := ulidAt(time.Unix(1469918176, 385000000))
+	assert.True(t, re.MatchString(id))
+	// the first 10 characters encode the time, example from the spec
+	assert.DeepEqual(t, "01ARYZ6S41", id[:10])
+	assert.True(t, ULID() > id)
+}
+
+func TestRequestID(t *testing.T) {
+	var line string
+	var ctxID interface{}
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${requestID} ${status}"),
+		WithRequestID(RequestID{Generator: func() string { return "generated" }}),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+			assert.DeepEqual(t, ctxID, ctx.Value(DefaultRequestIDContextKey))
+			line = format
+		}),
+	))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {
+		ctxID = RequestIDFromContext(ctx)
+	})
+
+	w := ut.PerformRequest(engine, "GET", "/", nil)
+	assert.DeepEqual(t, "generated 200", line)
+	assert.DeepEqual(t, "generated", ctxID)
+	assert.DeepEqual(t, "generated", w.Header().Get(DefaultRequestIDHeader))
+
+	w = ut.PerformRequest(engine, "GET", "/", nil, ut.Header{Key: "X-Request-Id", Value: "abc-123"})
+	assert.DeepEqual(t, "abc-123 200", line)
+	assert.DeepEqual(t, "abc-123", ctxID)
+	assert.DeepEqual(t, "abc-123", w.Header().Get(DefaultRequestIDHeader))
+
+	for _, bad := range []string{"a b", "a\x01", strings.Repeat("a", 129)} {
+		ut.PerformRequest(engine, "GET", "/", nil, ut.Header{Key: "X-Request-Id", Value: bad})
+		assert.DeepEqual(t, "generated 200", line)
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	type key struct{}
+	var ctxID interface{}
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithFormat("${requestID}"),
+		WithRequestID(RequestID{Header: "X-Trace", ContextKey: key{}, IgnoreIncoming: true}),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {}),
+	))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {
+		ctxID = ctx.Value(key{})
+	})
+	w := ut.PerformRequest(engine, "GET", "/", nil, ut.Header{Key: "X-Trace", Value: "abc"})
+	id := w.Header().Get("X-Trace")
+	assert.DeepEqual(t, 36, len(id))
+	assert.DeepEqual(t, id, ctxID)
+}
+
+func TestRequestIDTagWithoutOption(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t, "", renderFormat(t, "${requestID}", c, data))
+	c.Response.Header.Set(DefaultRequestIDHeader, "from-middleware")
+	assert.DeepEqual(t, "from-middleware", renderFormat(t, "${requestID}", c, data))
+}
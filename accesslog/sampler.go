@@ -0,0 +1,181 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SamplerConfig defines which access log lines a Sampler keeps. Every enabled strategy
+// has to keep a line for it to be logged, unless one of the keep rules matches.
+type SamplerConfig struct {
+	// Ratio is the fraction of lines kept, e.g. 0.1 keeps one line in ten on average.
+	//
+	// Optional. Default: 0, ratio sampling is disabled
+	Ratio float64
+
+	// Seed seeds the random source of Ratio so that sampling is reproducible.
+	//
+	// Optional. Default: 0, the source is seeded with the current time
+	Seed int64
+
+	// RouteRate is the number of lines per second kept for each route by a token bucket
+	// holding up to RouteBurst tokens.
+	//
+	// Optional. Default: 0, rate limiting is disabled. RouteBurst defaults to RouteRate
+	// rounded up, and to at least 1
+	RouteRate  float64
+	RouteBurst int
+
+	// First lines of each route are kept every Tick, after that only every Thereafter-th line.
+	//
+	// Optional. Default: 0, disabled. Tick defaults to one second
+	First      int
+	Thereafter int
+	Tick       time.Duration
+
+	// KeepErrors keeps every line of a response with a status outside 2xx.
+	//
+	// Optional. Default: false
+	KeepErrors bool
+
+	// KeepSlow keeps every line of a request whose latency is at least KeepSlow.
+	//
+	// Optional. Default: 0, disabled
+	KeepSlow time.Duration
+}
+
+// Sampler decides which access log lines are written and counts the kept and dropped lines.
+// A Sampler is safe for concurrent use and can be shared by several middlewares.
+type Sampler struct {
+	kept    uint64
+	dropped uint64
+
+	cfg    SamplerConfig
+	mu     sync.Mutex
+	rand   *rand.Rand
+	routes map[string]*routeSample
+	now    func() time.Time
+}
+
+// routeSample is the state of the token bucket and the tick counter of a route
+type routeSample struct {
+	tokens    float64
+	refilled  time.Time
+	tickStart time.Time
+	count     int
+}
+
+// NewSampler creates a Sampler, use it with WithSampler.
+func NewSampler(cfg SamplerConfig) *Sampler {
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+	if cfg.RouteRate > 0 && cfg.RouteBurst <= 0 {
+		cfg.RouteBurst = int(math.Ceil(cfg.RouteRate))
+		if cfg.RouteBurst < 1 {
+			cfg.RouteBurst = 1
+		}
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Sampler{
+		cfg:    cfg,
+		rand:   rand.New(rand.NewSource(seed)),
+		routes: make(map[string]*routeSample),
+		now:    time.Now,
+	}
+}
+
+// Sample reports whether the line of the request is kept.
+func (s *Sampler) Sample(c *app.RequestContext, latency time.Duration) bool {
+	if s.sample(c, latency) {
+		atomic.AddUint64(&s.kept, 1)
+		return true
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+// Kept returns the number of kept lines.
+func (s *Sampler) Kept() uint64 {
+	return atomic.LoadUint64(&s.kept)
+}
+
+// Dropped returns the number of dropped lines.
+func (s *Sampler) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Sampler) sample(c *app.RequestContext, latency time.Duration) bool {
+	cfg := &s.cfg
+	if status := c.Response.StatusCode(); cfg.KeepErrors && (status < 200 || status >= 300) {
+		return true
+	}
+	if cfg.KeepSlow > 0 && latency >= cfg.KeepSlow {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cfg.Ratio > 0 && s.rand.Float64() >= cfg.Ratio {
+		return false
+	}
+	if cfg.RouteRate <= 0 && cfg.First <= 0 {
+		return true
+	}
+
+	now := s.now()
+	route, ok := s.routes[c.FullPath()]
+	if !ok {
+		route = &routeSample{tokens: float64(cfg.RouteBurst), refilled: now, tickStart: now}
+		s.routes[c.FullPath()] = route
+	}
+
+	if cfg.First > 0 {
+		if now.Sub(route.tickStart) >= cfg.Tick {
+			route.tickStart = now
+			route.count = 0
+		}
+		route.count++
+		if route.count > cfg.First && (cfg.Thereafter <= 0 || (route.count-cfg.First)%cfg.Thereafter != 0) {
+			return false
+		}
+	}
+
+	if cfg.RouteRate > 0 {
+		route.tokens += now.Sub(route.refilled).Seconds() * cfg.RouteRate
+		if burst := float64(cfg.RouteBurst); route.tokens > burst {
+			route.tokens = burst
+		}
+		route.refilled = now
+		if route.tokens < 1 {
+			return false
+		}
+		route.tokens--
+	}
+	return true
+}
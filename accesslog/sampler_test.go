@@ -0,0 +1,142 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// sampleN runs n requests through the sampler and returns which were kept
+func sampleN(s *Sampler, c *app.RequestContext, n int, latency time.Duration) []bool {
+	kept := make([]bool, n)
+	for i := range kept {
+		kept[i] = s.Sample(c, latency)
+	}
+	return kept
+}
+
+func TestSamplerRatioDeterministic(t *testing.T) {
+	c := app.NewContext(0)
+	a := sampleN(NewSampler(SamplerConfig{Ratio: 0.3, Seed: 42}), c, 1000, 0)
+	b := sampleN(NewSampler(SamplerConfig{Ratio: 0.3, Seed: 42}), c, 1000, 0)
+	assert.DeepEqual(t, a, b)
+
+	kept := 0
+	for _, k := range a {
+		if k {
+			kept++
+		}
+	}
+	assert.True(t, kept > 200 && kept < 400)
+}
+
+func TestSamplerFirstThereafter(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSampler(SamplerConfig{First: 2, Thereafter: 3})
+	s.now = func() time.Time { return now }
+	c := app.NewContext(0)
+
+	assert.DeepEqual(t, []bool{true, true, false, false, true, false, false, true}, sampleN(s, c, 8, 0))
+	now = now.Add(time.Second)
+	assert.DeepEqual(t, []bool{true, true, false}, sampleN(s, c, 3, 0))
+	assert.DeepEqual(t, uint64(6), s.Kept())
+	assert.DeepEqual(t, uint64(5), s.Dropped())
+}
+
+func TestSamplerRouteRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSampler(SamplerConfig{RouteRate: 2, RouteBurst: 2})
+	s.now = func() time.Time { return now }
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(func(ctx context.Context, c *app.RequestContext) {
+		c.Next(ctx)
+		c.Set("kept", s.Sample(c, 0))
+	})
+	var kept []bool
+	record := func(ctx context.Context, c *app.RequestContext) {}
+	engine.GET("/a", record)
+	engine.GET("/b", record)
+	perform := func(path string) {
+		ctx := engine.NewContext()
+		ctx.Request.SetRequestURI(path)
+		engine.ServeHTTP(context.Background(), ctx)
+		kept = append(kept, ctx.GetBool("kept"))
+	}
+
+	for i := 0; i < 3; i++ {
+		perform("/a")
+	}
+	perform("/b")
+	now = now.Add(500 * time.Millisecond)
+	perform("/a")
+	perform("/a")
+	assert.DeepEqual(t, []bool{true, true, false, true, true, false}, kept)
+}
+
+func TestSamplerRouteRateDefaultBurst(t *testing.T) {
+	for rate, burst := range map[float64]int{3: 3, 2.5: 3, 0.5: 1} {
+		now := time.Unix(0, 0)
+		s := NewSampler(SamplerConfig{RouteRate: rate})
+		s.now = func() time.Time { return now }
+		assert.DeepEqual(t, burst, s.cfg.RouteBurst)
+		c := app.NewContext(0)
+		for i := 0; i < burst; i++ {
+			assert.True(t, s.Sample(c, 0))
+		}
+		assert.False(t, s.Sample(c, 0))
+		now = now.Add(time.Duration(float64(time.Second)/rate) + time.Millisecond)
+		assert.True(t, s.Sample(c, 0))
+	}
+}
+
+func TestSamplerKeepRules(t *testing.T) {
+	s := NewSampler(SamplerConfig{Ratio: 1e-9, Seed: 1, KeepErrors: true, KeepSlow: time.Second})
+	c := app.NewContext(0)
+	c.Response.SetStatusCode(200)
+	assert.False(t, s.Sample(c, time.Millisecond))
+	assert.True(t, s.Sample(c, time.Second))
+	c.Response.SetStatusCode(500)
+	assert.True(t, s.Sample(c, time.Millisecond))
+	c.Response.SetStatusCode(404)
+	assert.True(t, s.Sample(c, time.Millisecond))
+}
+
+func TestWithSampler(t *testing.T) {
+	var lines int
+	s := NewSampler(SamplerConfig{First: 1, KeepErrors: true})
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(New(
+		WithSampler(s),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) { lines++ }),
+	))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+	for i := 0; i < 5; i++ {
+		ut.PerformRequest(engine, "GET", "/", nil)
+	}
+	ut.PerformRequest(engine, "GET", "/missing", nil)
+	assert.DeepEqual(t, 2, lines)
+	assert.DeepEqual(t, uint64(2), s.Kept())
+	assert.DeepEqual(t, uint64(4), s.Dropped())
+}
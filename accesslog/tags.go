@@ -42,12 +42,15 @@
 package accesslog
 
 import (
+	"crypto/tls"
 	"fmt"
-	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/protocol"
 )
 
 const (
@@ -74,102 +77,478 @@ const (
 	TagBytesSent         = "bytesSent"
 	TagBytesReceived     = "bytesReceived"
 	TagRoute             = "route"
+	TagRemoteAddr        = "remoteAddr"
+	TagRemoteUser        = "remoteUser"
+	TagRequestLine       = "requestLine"
+	TagCLFTime           = "clfTime"
+	TagCLFBytesSent      = "clfBytesSent"
+	TagCLFReferer        = "clfReferer"
+	TagCLFUA             = "clfUA"
+	TagCLFForwardedFor   = "clfForwardedFor"
+	TagStartTime         = "startTime"
+	TagEndTime           = "endTime"
+	TagUnix              = "unix"
+	TagUnixMilli         = "unixMilli"
+	TagUnixNano          = "unixNano"
+	TagRequestID         = "requestID"
+	TagTraceID           = "traceID"
+	TagSpanID            = "spanID"
+	TagTraceFlags        = "traceFlags"
+	TagTraceState        = "traceState"
+	TagError             = "error"
+	TagErrors            = "errors"
+	TagErrorType         = "errorType"
+	TagReadHeaderLatency = "readHeaderLatency"
+	TagReadBodyLatency   = "readBodyLatency"
+	TagHandleLatency     = "handleLatency"
+	TagRecvSize          = "recvSize"
+	TagTLSVersion        = "tlsVersion"
+	TagTLSCipher         = "tlsCipher"
+	TagTLSServerName     = "tlsServerName"
+	TagTLSALPN           = "tlsALPN"
+	TagTLSClientSubject  = "tlsClientSubject"
+	TagTLSClientFP       = "tlsClientFingerprint"
+	TagPeerAddr          = "peerAddr"
+	TagLocalAddr         = "localAddr"
+	TagConnReused        = "connReused"
+	TagUABrowser         = "uaBrowser"
+	TagUABrowserVersion  = "uaBrowserVersion"
+	TagUAOS              = "uaOS"
+	TagUADevice          = "uaDevice"
+	TagUABot             = "uaBot"
+
+	// tags with a parameter, e.g. ${reqHeader:X-Request-Id}
+	TagReqHeader   = "reqHeader:"
+	TagRespHeader  = "respHeader:"
+	TagQuery       = "query:"
+	TagCookie      = "cookie:"
+	TagParam       = "param:"
+	TagForm        = "form:"
+	TagLocals      = "locals:"
+	TagTimeLayout  = "time:"
+	TagLatencyUnit = "latency:"
 )
 
 type LogFunc func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error)
 
 // Data is a struct to define some variables to use in custom logger function.
 type Data struct {
-	Pid       string
-	Start     time.Time
-	Stop      time.Time
+	Pid   string
+	Start time.Time
+	Stop  time.Time
+	// RequestID is set with WithRequestID
+	RequestID string
+	// Trace is set with WithTraceExtractor
+	Trace TraceContext
+
+	connReused bool
+	userAgent  *userAgent
+	// Timestamp is only refreshed with WithCachedTimestamp, otherwise use Start
 	Timestamp atomic.Value
+
+	cfg *options
+}
+
+// options returns the config of the middleware instance that logs the request
+func (d *Data) options() *options {
+	if d.cfg == nil {
+		return defaultOptions
+	}
+	return d.cfg
 }
 
 var Tags = map[string]LogFunc{
 	TagReferer: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(c.Request.Header.Get("Referer"))
+		rd := data.options().redactor
+		return rd.writeValue(output, c.Request.Header.Peek("Referer"), rd.header([]byte("Referer")))
 	},
 	TagProtocol: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Request.URI().Scheme()))
+		return output.Write(c.Request.URI().Scheme())
 	},
 	TagPort: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		host := string(c.Request.URI().Host())
-		split := strings.Split(host, ":")
-		return output.WriteString(split[1])
+		return output.WriteString(requestPort(c))
 	},
 	TagIP: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		host := string(c.Request.URI().Host())
-		split := strings.Split(host, ":")
-		return output.WriteString(split[0])
+		host, _ := splitHost(unsafeString(c.Request.URI().Host()))
+		return data.options().ipAnonymizer.writeIP(output, host)
 	},
 	TagIPs: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(c.Request.Header.Get("X-Forwarded-For"))
+		return data.options().ipAnonymizer.writeList(output, c.Request.Header.Peek("X-Forwarded-For"))
 	},
 	TagResBody: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Response.Body()))
+		if c.Response.IsBodyStream() && data.options().bodyCapture != nil {
+			return 0, nil
+		}
+		return writeBody(output, data, c.Response.Header.ContentType(), c.Response.Body())
 	},
 	TagHost: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Request.URI().Host()))
+		return output.Write(c.Request.URI().Host())
 	},
 	TagClientIP: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(c.ClientIP())
+		return data.options().ipAnonymizer.writeIP(output, c.ClientIP())
 	},
 	TagPath: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Request.Path()))
+		return output.Write(c.Request.Path())
 	},
 	TagURL: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Request.Header.RequestURI()))
+		return data.options().redactor.writeURI(output, c.Request.Header.RequestURI())
 	},
 	TagUA: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(c.Request.Header.Get("User-Agent"))
+		rd := data.options().redactor
+		return rd.writeValue(output, c.Request.Header.Peek("User-Agent"), rd.header([]byte("User-Agent")))
 	},
 	TagBody: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.Write(c.Request.Body())
+		if c.Request.IsBodyStream() && data.options().bodyCapture != nil {
+			return 0, nil
+		}
+		return writeBody(output, data, c.Request.Header.ContentType(), c.Request.Body())
 	},
 	TagBytesSent: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		if c.Response.Header.ContentLength() < 0 {
-			return appendInt(output, 0)
-		}
-		return appendInt(output, len(c.Response.Body()))
+		return appendInt(output, bytesSent(c))
 	},
 	TagBytesReceived: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return appendInt(output, len(c.Request.Body()))
+		if c.Request.IsBodyStream() {
+			if n := c.Request.Header.ContentLength(); n > 0 {
+				return appendInt(output, n)
+			}
+			return appendInt(output, 0)
+		}
+		return appendInt(output, len(c.Request.BodyBytes()))
 	},
 	TagRoute: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Path()))
+		return output.Write(c.Path())
 	},
 	TagStatus: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
 		return appendInt(output, c.Response.StatusCode())
 	},
 	TagReqHeaders: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		reqHeaders := make([]string, 0)
-		c.Request.Header.VisitAll(func(k, v []byte) {
-			reqHeaders = append(reqHeaders, string(k)+"="+string(v))
-		})
-		return output.Write([]byte(strings.Join(reqHeaders, "&")))
+		return writeRequestHeaders(output, data.options().redactor, &c.Request.Header)
 	},
 	TagResHeaders: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		resHeaders := make([]string, 0)
-		c.Response.Header.VisitAll(func(k, v []byte) {
-			resHeaders = append(resHeaders, string(k)+"="+string(v))
-		})
-		return output.Write([]byte(strings.Join(resHeaders, "&")))
+		return writeResponseHeaders(output, data.options().redactor, &c.Response.Header)
 	},
 	TagQueryStringParams: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(c.Request.URI().QueryArgs().String())
+		rd := data.options().redactor
+		if rd == nil {
+			old := output.Len()
+			output.Set(c.Request.URI().QueryArgs().AppendBytes(output.Bytes()))
+			return output.Len() - old, nil
+		}
+		old := output.Len()
+		output.Set(rd.appendPairs(output.Bytes(), c.Request.URI().QueryString(), rd.queryParam))
+		rd.redactPatterns(output, old)
+		return output.Len() - old, nil
 	},
 	TagMethod: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(string(c.Method()))
+		return output.Write(c.Method())
 	},
 	TagLatency: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
 		latency := data.Stop.Sub(data.Start)
-		return output.WriteString(fmt.Sprintf("%13v", latency))
+		if unit := data.options().latencyUnit; unit != "" {
+			return writeLatency(output, latency, unit)
+		}
+		old := output.Len()
+		var b [32]byte
+		output.Set(appendPadded(output.Bytes(), appendDuration(b[:0], latency), 13))
+		return output.Len() - old, nil
+	},
+	TagLatencyUnit: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeLatency(output, data.Stop.Sub(data.Start), extraParam)
 	},
 	TagPid: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
 		return output.WriteString(data.Pid)
 	},
 	TagTime: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
-		return output.WriteString(data.Timestamp.Load().(string))
+		cfg := data.options()
+		if cfg.cachedTime {
+			return output.WriteString(data.Timestamp.Load().(string))
+		}
+		return appendTime(output, data.Start, cfg.timeZoneLocation, cfg.timeFormat)
+	},
+	TagTimeLayout: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendTime(output, data.Start, data.options().timeZoneLocation, extraParam)
+	},
+	TagStartTime: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendTime(output, data.Start, data.options().timeZoneLocation, time.RFC3339Nano)
+	},
+	TagEndTime: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendTime(output, data.Stop, data.options().timeZoneLocation, time.RFC3339Nano)
+	},
+	TagUnix: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendInt(output, int(data.Start.Unix()))
+	},
+	TagUnixMilli: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendInt(output, int(data.Start.UnixNano()/int64(time.Millisecond)))
+	},
+	TagUnixNano: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendInt(output, int(data.Start.UnixNano()))
+	},
+	TagRequestID: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if data.RequestID != "" {
+			return output.WriteString(data.RequestID)
+		}
+		// set by another middleware
+		return output.Write(c.Response.Header.Peek(DefaultRequestIDHeader))
+	},
+	TagTraceID: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if t, ok := traceContext(c, data); ok {
+			return appendHex(output, t.TraceID[:])
+		}
+		return 0, nil
+	},
+	TagSpanID: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if t, ok := traceContext(c, data); ok {
+			return appendHex(output, t.SpanID[:])
+		}
+		return 0, nil
+	},
+	TagTraceFlags: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if t, ok := traceContext(c, data); ok {
+			return appendHex(output, []byte{t.TraceFlags})
+		}
+		return 0, nil
+	},
+	TagTraceState: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		t, _ := traceContext(c, data)
+		return output.WriteString(t.TraceState)
+	},
+	TagError: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if err := c.Errors.Last(); err != nil {
+			return writeError(output, data, err)
+		}
+		return 0, nil
+	},
+	TagErrors: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeErrors(output, data, c.Errors)
+	},
+	TagErrorType: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if err := c.Errors.Last(); err != nil {
+			return writeErrorType(output, err)
+		}
+		return 0, nil
+	},
+	TagReadHeaderLatency: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		latency, ok := phaseLatency(c, stats.ReadHeaderStart, stats.ReadHeaderFinish, time.Time{})
+		return writePhaseLatency(output, data, latency, ok)
+	},
+	TagReadBodyLatency: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		latency, ok := phaseLatency(c, stats.ReadBodyStart, stats.ReadBodyFinish, time.Time{})
+		return writePhaseLatency(output, data, latency, ok)
+	},
+	TagHandleLatency: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		latency, ok := phaseLatency(c, stats.ServerHandleStart, stats.ServerHandleFinish, handleEnd(data))
+		return writePhaseLatency(output, data, latency, ok)
+	},
+	TagRecvSize: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		st := traceStats(c)
+		if st == nil || st.GetEvent(stats.HTTPStart) == nil {
+			return output.WriteString("-")
+		}
+		return appendInt(output, st.RecvSize())
+	},
+	TagTLSVersion: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeTLSVersion(output, c)
+	},
+	TagTLSCipher: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if state, ok := tlsState(c); ok {
+			return output.WriteString(tls.CipherSuiteName(state.CipherSuite))
+		}
+		return output.WriteString("-")
+	},
+	TagTLSServerName: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		state, _ := tlsState(c)
+		return writeOrDash(output, state.ServerName)
+	},
+	TagTLSALPN: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		state, _ := tlsState(c)
+		return writeOrDash(output, state.NegotiatedProtocol)
 	},
+	TagTLSClientSubject: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		_, subject, _ := clientCert(c)
+		return writeCLFEscaped(output, unsafeBytes(subject))
+	},
+	TagTLSClientFP: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeClientFingerprint(output, c)
+	},
+	TagPeerAddr: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeSocketAddr(output, c.RemoteAddr(), data.options().ipAnonymizer)
+	},
+	TagLocalAddr: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if conn := c.GetConn(); conn != nil {
+			return writeSocketAddr(output, conn.LocalAddr(), nil)
+		}
+		return output.WriteString("-")
+	},
+	TagConnReused: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if data.options().conns == nil {
+			return output.WriteString("-")
+		}
+		if data.connReused {
+			return output.WriteString("true")
+		}
+		return output.WriteString("false")
+	},
+	TagUABrowser: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeUserAgent(output, c, data, func(ua *userAgent) string { return ua.browser })
+	},
+	TagUABrowserVersion: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeUserAgent(output, c, data, func(ua *userAgent) string { return ua.version })
+	},
+	TagUAOS: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeUserAgent(output, c, data, func(ua *userAgent) string { return ua.os })
+	},
+	TagUADevice: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeUserAgent(output, c, data, func(ua *userAgent) string { return ua.device })
+	},
+	TagUABot: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeUserAgent(output, c, data, func(ua *userAgent) string {
+			if ua.bot {
+				return "true"
+			}
+			return "false"
+		})
+	},
+	TagRemoteAddr: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if ip := remoteIP(c); ip != "" {
+			return data.options().ipAnonymizer.writeIP(output, ip)
+		}
+		return output.WriteString("-")
+	},
+	TagRemoteUser: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		username, _, _ := c.Request.BasicAuth()
+		return writeCLFEscaped(output, unsafeBytes(username))
+	},
+	TagRequestLine: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		old := output.Len()
+		_, _ = writeCLFEscaped(output, c.Method())
+		_ = output.WriteByte(' ')
+		if rd := data.options().redactor; rd != nil {
+			uri := bytebufferpool.Get()
+			_, _ = rd.writeURI(uri, c.Request.Header.RequestURI())
+			_, _ = writeCLFEscaped(output, uri.B)
+			bytebufferpool.Put(uri)
+		} else {
+			_, _ = writeCLFEscaped(output, c.Request.Header.RequestURI())
+		}
+		_ = output.WriteByte(' ')
+		_, _ = writeCLFEscaped(output, unsafeBytes(c.Request.Header.GetProtocol()))
+		return output.Len() - old, nil
+	},
+	TagCLFTime: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return appendTime(output, data.Start, data.options().timeZoneLocation, clfTimeFormat)
+	},
+	TagCLFBytesSent: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if n := bytesSent(c); n > 0 {
+			return appendInt(output, n)
+		}
+		return output.WriteString("-")
+	},
+	TagCLFReferer: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeCLFHeader(output, c, data, "Referer")
+	},
+	TagCLFUA: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return writeCLFHeader(output, c, data, "User-Agent")
+	},
+	TagCLFForwardedFor: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		if an := data.options().ipAnonymizer; an != nil {
+			return writeCLFEscaped(output, an.appendList(nil, c.Request.Header.Peek("X-Forwarded-For")))
+		}
+		return writeCLFHeader(output, c, data, "X-Forwarded-For")
+	},
+	TagReqHeader: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		rd := data.options().redactor
+		return rd.writeValue(output, c.Request.Header.Peek(extraParam), rd.header(unsafeBytes(extraParam)))
+	},
+	TagRespHeader: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		rd := data.options().redactor
+		return rd.writeValue(output, c.Response.Header.Peek(extraParam), rd.header(unsafeBytes(extraParam)))
+	},
+	TagQuery: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		rd := data.options().redactor
+		return rd.writeValue(output, c.QueryArgs().Peek(extraParam), rd.queryParam(unsafeBytes(extraParam)))
+	},
+	TagCookie: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		rd := data.options().redactor
+		return rd.writeValue(output, c.Cookie(extraParam), rd != nil && rd.hasCookies)
+	},
+	TagParam: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return output.WriteString(c.Param(extraParam))
+	},
+	TagForm: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		rd := data.options().redactor
+		return rd.writeValue(output, c.FormValue(extraParam), rd.formField(unsafeBytes(extraParam)))
+	},
+	TagLocals: func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		value, _ := c.Get(extraParam)
+		switch v := value.(type) {
+		case nil:
+			return 0, nil
+		case string:
+			return output.WriteString(v)
+		case []byte:
+			return output.Write(v)
+		case fmt.Stringer:
+			return output.WriteString(v.String())
+		default:
+			return fmt.Fprint(output, v)
+		}
+	},
+}
+
+// appendTime writes t formatted with layout in loc
+func appendTime(output Buffer, t time.Time, loc *time.Location, layout string) (int, error) {
+	old := output.Len()
+	output.Set(t.In(loc).AppendFormat(output.Bytes(), layout))
+	return output.Len() - old, nil
+}
+
+// bytesSent returns the size of the response body without reading a body stream
+func bytesSent(c *app.RequestContext) int {
+	if c.Response.IsBodyStream() {
+		if n := c.Response.Header.ContentLength(); n > 0 {
+			return n
+		}
+		return 0
+	}
+	return len(c.Response.BodyBytes())
+}
+
+// writeRequestHeaders writes the request headers as k=v pairs separated by "&", the values of
+// redacted headers are masked
+func writeRequestHeaders(output Buffer, rd *redactor, h *protocol.RequestHeader) (int, error) {
+	old := output.Len()
+	a := headerAppender{dst: output.Bytes(), start: old, rd: rd}
+	h.VisitAll(a.add)
+	output.Set(a.dst)
+	rd.redactPatterns(output, old)
+	return output.Len() - old, nil
+}
+
+// writeResponseHeaders writes the response headers like writeRequestHeaders
+func writeResponseHeaders(output Buffer, rd *redactor, h *protocol.ResponseHeader) (int, error) {
+	old := output.Len()
+	a := headerAppender{dst: output.Bytes(), start: old, rd: rd}
+	h.VisitAll(a.add)
+	output.Set(a.dst)
+	rd.redactPatterns(output, old)
+	return output.Len() - old, nil
+}
+
+type headerAppender struct {
+	dst   []byte
+	start int
+	rd    *redactor
+}
+
+func (a *headerAppender) add(k, v []byte) {
+	if len(a.dst) > a.start {
+		a.dst = append(a.dst, '&')
+	}
+	a.dst = append(a.dst, k...)
+	a.dst = append(a.dst, '=')
+	if a.rd.header(k) {
+		a.dst = a.rd.appendMask(a.dst, v)
+	} else {
+		a.dst = append(a.dst, v...)
+	}
 }
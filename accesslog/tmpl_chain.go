@@ -46,6 +46,8 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -80,7 +82,6 @@ func buildLogFuncChain(cfg *options, tagFunctions map[string]LogFunc) ([][]byte,
 	templateB := unsafeBytes(cfg.format)
 	startTagB := unsafeBytes(startTag)
 	endTagB := unsafeBytes(endTag)
-	paramSeparatorB := unsafeBytes(paramSeparator)
 
 	var fixParts [][]byte
 	var funcChain []LogFunc
@@ -104,19 +105,13 @@ func buildLogFuncChain(cfg *options, tagFunctions map[string]LogFunc) ([][]byte,
 			break
 		}
 		// ## function block ##
-		// first check for tags with parameters
-		if index := bytes.Index(templateB[:currentPos], paramSeparatorB); index != -1 {
-			logFunc, ok := tagFunctions[unsafeString(templateB[:index+1])]
-			if !ok {
-				return nil, nil, errors.New("No parameter found in \"" + unsafeString(templateB[:currentPos]) + "\"")
-			}
-			funcChain = append(funcChain, logFunc)
-			// add param to the fixParts
-			fixParts = append(fixParts, templateB[index+1:currentPos])
-		} else if logFunc, ok := tagFunctions[unsafeString(templateB[:currentPos])]; ok {
-			// add functions without parameter
+		logFunc, param, ok := lookupTag(templateB[:currentPos], tagFunctions)
+		if ok {
 			funcChain = append(funcChain, logFunc)
-			fixParts = append(fixParts, nil)
+			// add param to the fixParts, nil for functions without parameter
+			fixParts = append(fixParts, param)
+		} else if bytes.Contains(templateB[:currentPos], unsafeBytes(paramSeparator)) {
+			return nil, nil, errors.New("No parameter found in \"" + unsafeString(templateB[:currentPos]) + "\"")
 		}
 		// ## function block end ##
 
@@ -130,6 +125,121 @@ func buildLogFuncChain(cfg *options, tagFunctions map[string]LogFunc) ([][]byte,
 	return fixParts, funcChain, nil
 }
 
+// FormatError describes a problem of a format at a byte offset.
+type FormatError struct {
+	// Offset is the byte offset of the "${" that starts the tag
+	Offset int
+	// Tag is the text between "${" and "}"
+	Tag    string
+	Reason string
+}
+
+func (e *FormatError) Error() string {
+	return "offset " + strconv.Itoa(e.Offset) + ": " + e.Reason + " \"" + e.Tag + "\""
+}
+
+// FormatErrors lists every problem of a format.
+type FormatErrors []*FormatError
+
+func (e FormatErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "invalid format: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks format against the built-in tags and returns FormatErrors listing every
+// unknown tag, unterminated "${" and missing parameter, or nil if format is valid.
+func Validate(format string) error {
+	return validateFormat(format, Tags)
+}
+
+func validateFormat(format string, tagFunctions map[string]LogFunc) error {
+	var errs FormatErrors
+	for offset := 0; ; {
+		start := strings.Index(format[offset:], startTag)
+		if start < 0 {
+			break
+		}
+		start += offset
+		end := strings.Index(format[start+len(startTag):], endTag)
+		if end < 0 {
+			errs = append(errs, &FormatError{Offset: start, Tag: format[start+len(startTag):], Reason: "unterminated tag"})
+			break
+		}
+		end += start + len(startTag)
+		tag := format[start+len(startTag) : end]
+		offset = end + len(endTag)
+
+		_, param, ok := lookupTag(unsafeBytes(tag), tagFunctions)
+		switch {
+		case tag == "":
+			errs = append(errs, &FormatError{Offset: start, Tag: tag, Reason: "empty tag"})
+		case !ok:
+			errs = append(errs, &FormatError{Offset: start, Tag: tag, Reason: "unknown tag"})
+		case param != nil && len(param) == 0:
+			errs = append(errs, &FormatError{Offset: start, Tag: tag, Reason: "empty parameter in tag"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// buildTags merges a copy of Tags with the custom tags of an instance, custom tags can not
+// override a tag of Tags or another custom tag
+func buildTags(custom []map[string]LogFunc) (map[string]LogFunc, error) {
+	tags := make(map[string]LogFunc, len(Tags))
+	for name, logFunc := range Tags {
+		tags[name] = logFunc
+	}
+	for _, m := range custom {
+		for name, logFunc := range m {
+			if err := validateTagName(name); err != nil {
+				return nil, err
+			}
+			if logFunc == nil {
+				return nil, errors.New("Custom tag \"" + name + "\" has no function")
+			}
+			if _, ok := Tags[name]; ok {
+				return nil, errors.New("Custom tag \"" + name + "\" collides with the built-in tag, choose another name")
+			}
+			if _, ok := tags[name]; ok {
+				return nil, errors.New("Custom tag \"" + name + "\" is registered more than once")
+			}
+			tags[name] = logFunc
+		}
+	}
+	return tags, nil
+}
+
+// validateTagName checks that name can be used in a template, tags with parameters end with the separator
+func validateTagName(name string) error {
+	switch {
+	case name == "" || name == paramSeparator:
+		return errors.New("Custom tag name is empty")
+	case strings.Contains(name, startTag) || strings.Contains(name, endTag):
+		return errors.New("Custom tag \"" + name + "\" must not contain \"" + startTag + "\" or \"" + endTag + "\"")
+	case strings.Contains(strings.TrimSuffix(name, paramSeparator), paramSeparator):
+		return errors.New("Custom tag \"" + name + "\" may only contain \"" + paramSeparator + "\" as its last character")
+	}
+	return nil
+}
+
+// lookupTag returns the function registered for tag. Tags with parameters are registered
+// with a trailing separator ("tag:") and their parameter is returned as param, which is nil
+// for tags without parameters.
+func lookupTag(tag []byte, tagFunctions map[string]LogFunc) (logFunc LogFunc, param []byte, ok bool) {
+	if index := bytes.Index(tag, unsafeBytes(paramSeparator)); index != -1 {
+		logFunc, ok = tagFunctions[unsafeString(tag[:index+1])]
+		return logFunc, tag[index+1:], ok
+	}
+	logFunc, ok = tagFunctions[unsafeString(tag)]
+	return logFunc, nil, ok
+}
+
 const MaxStringLen = 0x7fff0000 // Maximum string length for UnsafeBytes. (decimal: 2147418112)
 
 func unsafeBytes(s string) []byte {
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestCustomTagsPerInstance(t *testing.T) {
+	var lines []string
+	logFunc := func(ctx context.Context, format string, v ...interface{}) {
+		lines = append(lines, format)
+	}
+	tenant := func(name string) map[string]LogFunc {
+		return map[string]LogFunc{
+			"tenant": func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+				return output.WriteString(name)
+			},
+			"greet:": func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+				return output.WriteString(name + " greets " + extraParam)
+			},
+		}
+	}
+
+	a := route.NewEngine(config.NewOptions([]config.Option{}))
+	a.Use(New(WithFormat("${tenant}|${greet:bob}"), WithCustomTags(tenant("a")), WithAccessLogFunc(logFunc)))
+	b := route.NewEngine(config.NewOptions([]config.Option{}))
+	b.Use(New(WithFormat("${tenant}|${greet:bob}"), WithCustomTags(tenant("b")), WithAccessLogFunc(logFunc)))
+	ut.PerformRequest(a, "GET", "/", nil)
+	ut.PerformRequest(b, "GET", "/", nil)
+
+	assert.DeepEqual(t, []string{"a|a greets bob", "b|b greets bob"}, lines)
+	_, ok := Tags["tenant"]
+	assert.False(t, ok)
+}
+
+func TestCustomTagsErrors(t *testing.T) {
+	logFunc := func(output Buffer, c *app.RequestContext, data *Data, extraParam string) (int, error) {
+		return 0, nil
+	}
+	for _, tc := range []struct {
+		tags []map[string]LogFunc
+		err  string
+	}{
+		{[]map[string]LogFunc{{TagStatus: logFunc}}, `"status" collides with the built-in tag`},
+		{[]map[string]LogFunc{{TagReqHeader: logFunc}}, `"reqHeader:" collides with the built-in tag`},
+		{[]map[string]LogFunc{{"a": logFunc}, {"a": logFunc}}, `"a" is registered more than once`},
+		{[]map[string]LogFunc{{"": logFunc}}, "name is empty"},
+		{[]map[string]LogFunc{{"a}": logFunc}}, `must not contain`},
+		{[]map[string]LogFunc{{"a:b": logFunc}}, `may only contain ":" as its last character`},
+		{[]map[string]LogFunc{{"a": nil}}, `has no function`},
+	} {
+		_, err := buildTags(tc.tags)
+		assert.NotNil(t, err)
+		assert.True(t, strings.Contains(err.Error(), tc.err))
+	}
+
+	tags, err := buildTags([]map[string]LogFunc{{"a": logFunc, "a:": logFunc}})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, len(Tags)+2, len(tags))
+}
+
+func TestCustomTagsPanic(t *testing.T) {
+	defer func() {
+		assert.NotNil(t, recover())
+	}()
+	New(WithCustomTags(map[string]LogFunc{TagPath: Tags[TagPath]}))
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate(defaultTagFormat))
+	assert.Nil(t, Validate(FormatCombined))
+	assert.Nil(t, Validate("no tags at all"))
+	assert.Nil(t, Validate("${reqHeader:X-Request-Id} ${query:page}"))
+
+	err := Validate("[${time}] ${stauts} ${query:} ${} ${nope:x} ${path")
+	errs, ok := err.(FormatErrors)
+	assert.True(t, ok)
+	assert.DeepEqual(t, FormatErrors{
+		{Offset: 10, Tag: "stauts", Reason: "unknown tag"},
+		{Offset: 20, Tag: "query:", Reason: "empty parameter in tag"},
+		{Offset: 30, Tag: "", Reason: "empty tag"},
+		{Offset: 34, Tag: "nope:x", Reason: "unknown tag"},
+		{Offset: 44, Tag: "path", Reason: "unterminated tag"},
+	}, errs)
+	assert.DeepEqual(t, `invalid format: offset 10: unknown tag "stauts"; offset 20: empty parameter in tag "query:"; `+
+		`offset 30: empty tag ""; offset 34: unknown tag "nope:x"; offset 44: unterminated tag "path"`, err.Error())
+}
+
+func TestStrictFormat(t *testing.T) {
+	assert.NotPanic(t, func() {
+		New(WithFormat("${status} ${custom}"), WithStrictFormat(), WithCustomTags(map[string]LogFunc{
+			"custom": Tags[TagStatus],
+		}))
+	})
+	assert.NotPanic(t, func() {
+		// unknown tags are dropped without strict mode
+		New(WithFormat("${stauts}"))
+	})
+	assert.Panic(t, func() {
+		New(WithFormat("${stauts}"), WithStrictFormat())
+	})
+}
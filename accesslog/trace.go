@@ -0,0 +1,144 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+
+	maxTraceStateLen = 512
+)
+
+// TraceContext is the W3C trace context of a request, see https://www.w3.org/TR/trace-context/
+type TraceContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	TraceState string
+}
+
+// IsValid reports whether the trace and span IDs are set
+func (t *TraceContext) IsValid() bool {
+	return t.TraceID != [16]byte{} && t.SpanID != [8]byte{}
+}
+
+// TraceExtractor returns the trace context of the span stored in ctx, or a zero TraceContext.
+// With OpenTelemetry:
+//
+//	func(ctx context.Context) accesslog.TraceContext {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return accesslog.TraceContext{
+//			TraceID:    sc.TraceID(),
+//			SpanID:     sc.SpanID(),
+//			TraceFlags: byte(sc.TraceFlags()),
+//			TraceState: sc.TraceState().String(),
+//		}
+//	}
+type TraceExtractor func(ctx context.Context) TraceContext
+
+// traceContext returns the trace context of the span in ctx if WithTraceExtractor found one,
+// otherwise the one of the traceparent and tracestate request headers
+func traceContext(c *app.RequestContext, data *Data) (TraceContext, bool) {
+	if data.Trace.IsValid() {
+		return data.Trace, true
+	}
+	t, ok := parseTraceParent(c.Request.Header.Peek(headerTraceParent))
+	if !ok {
+		return t, false
+	}
+	if state := c.Request.Header.Peek(headerTraceState); validTraceState(state) {
+		t.TraceState = string(state)
+	}
+	return t, true
+}
+
+// parseTraceParent parses a traceparent header such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceParent(v []byte) (t TraceContext, ok bool) {
+	if len(v) < 55 || v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return t, false
+	}
+	var version [1]byte
+	if !decodeHex(version[:], v[:2]) || version[0] == 0xff {
+		return t, false
+	}
+	// version 00 has exactly four fields, later versions may append more
+	if (version[0] == 0 && len(v) != 55) || (len(v) > 55 && v[55] != '-') {
+		return t, false
+	}
+	var flags [1]byte
+	if !decodeHex(t.TraceID[:], v[3:35]) || !decodeHex(t.SpanID[:], v[36:52]) || !decodeHex(flags[:], v[53:55]) {
+		return TraceContext{}, false
+	}
+	t.TraceFlags = flags[0]
+	if !t.IsValid() {
+		return TraceContext{}, false
+	}
+	return t, true
+}
+
+// decodeHex decodes lowercase hex as required by traceparent
+func decodeHex(dst, src []byte) bool {
+	for i := range dst {
+		hi, ok1 := fromHex(src[2*i])
+		lo, ok2 := fromHex(src[2*i+1])
+		if !ok1 || !ok2 {
+			return false
+		}
+		dst[i] = hi<<4 | lo
+	}
+	return true
+}
+
+func fromHex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	}
+	return 0, false
+}
+
+// validTraceState only accepts printable ASCII so the header can not break the log line
+func validTraceState(v []byte) bool {
+	if len(v) == 0 || len(v) > maxTraceStateLen {
+		return false
+	}
+	for _, b := range v {
+		if b < ' ' || b > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func appendHex(output Buffer, b []byte) (int, error) {
+	old := output.Len()
+	dst := output.Bytes()
+	for _, v := range b {
+		dst = append(dst, hex[v>>4], hex[v&0x0f])
+	}
+	output.Set(dst)
+	return output.Len() - old, nil
+}
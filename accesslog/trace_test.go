@@ -0,0 +1,94 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const traceFormat = "${traceID} ${spanID} ${traceFlags} ${traceState}"
+
+func TestParseTraceParent(t *testing.T) {
+	for v, ok := range map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        true,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future": true,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra":  false,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01extra":   false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":        false,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7":           false,
+		"": false,
+	} {
+		_, got := parseTraceParent([]byte(v))
+		assert.DeepEqual(t, ok, got)
+	}
+}
+
+func TestTraceTags(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t, "   ", renderFormat(t, traceFormat, c, data))
+
+	c.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	c.Request.Header.Set("tracestate", "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7")
+	assert.DeepEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7 01 congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+		renderFormat(t, traceFormat, c, data))
+
+	c.Request.Header.Set("tracestate", "a=1\x7f")
+	assert.DeepEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7 01 ", renderFormat(t, traceFormat, c, data))
+
+	// tracestate is ignored without a valid traceparent
+	c.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7")
+	c.Request.Header.Set("tracestate", "a=1")
+	assert.DeepEqual(t, "   ", renderFormat(t, traceFormat, c, data))
+}
+
+type spanKey struct{}
+
+func TestTraceExtractor(t *testing.T) {
+	var line string
+	engine := route.NewEngine(config.NewOptions([]config.Option{}))
+	engine.Use(func(ctx context.Context, c *app.RequestContext) {
+		span := TraceContext{TraceID: [16]byte{0: 1, 15: 2}, SpanID: [8]byte{7: 3}, TraceState: "k=v"}
+		c.Next(context.WithValue(ctx, spanKey{}, span))
+	})
+	engine.Use(New(
+		WithFormat(traceFormat),
+		WithTraceExtractor(func(ctx context.Context) TraceContext {
+			t, _ := ctx.Value(spanKey{}).(TraceContext)
+			return t
+		}),
+		WithAccessLogFunc(func(ctx context.Context, format string, v ...interface{}) {
+			line = format
+		}),
+	))
+	engine.GET("/", func(ctx context.Context, c *app.RequestContext) {})
+
+	ut.PerformRequest(engine, "GET", "/", nil,
+		ut.Header{Key: "traceparent", Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	assert.DeepEqual(t, "01000000000000000000000000000002 0000000000000003 00 k=v", line)
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
+)
+
+// The trace stats tags need the server to run with tracing enabled, e.g. server.WithTracer,
+// and write "-" otherwise. The response is written after the middleware returns, so there are
+// no tags for the write phase and ${handleLatency} ends when the middleware logs.
+
+// traceStats returns the trace stats of the request, or nil if tracing is disabled
+func traceStats(c *app.RequestContext) traceinfo.HTTPStats {
+	if ti := c.GetTraceInfo(); ti != nil {
+		return ti.Stats()
+	}
+	return nil
+}
+
+// phaseLatency returns the time between the start and finish events, a missing finish event
+// is replaced by end if it is not zero
+func phaseLatency(c *app.RequestContext, start, finish stats.Event, end time.Time) (time.Duration, bool) {
+	st := traceStats(c)
+	if st == nil {
+		return 0, false
+	}
+	s := st.GetEvent(start)
+	if s == nil {
+		return 0, false
+	}
+	if f := st.GetEvent(finish); f != nil {
+		end = f.Time()
+	}
+	if end.IsZero() {
+		return 0, false
+	}
+	return end.Sub(s.Time()), true
+}
+
+// writePhaseLatency writes the latency like ${latency} without padding, or "-" if it is unknown.
+// Structured encoders get nanoseconds like the latency field, so the value stays a number.
+func writePhaseLatency(output Buffer, data *Data, latency time.Duration, ok bool) (int, error) {
+	if !ok {
+		return output.WriteString("-")
+	}
+	if unit := data.options().latencyUnit; unit != "" {
+		return writeLatency(output, latency, unit)
+	}
+	if len(data.options().fields) > 0 {
+		return appendInt(output, int(latency))
+	}
+	old := output.Len()
+	output.Set(appendDuration(output.Bytes(), latency))
+	return output.Len() - old, nil
+}
+
+// handleEnd is the end of the handle phase when the server has not recorded it yet
+func handleEnd(data *Data) time.Time {
+	if !data.Stop.IsZero() {
+		return data.Stop
+	}
+	return time.Now()
+}
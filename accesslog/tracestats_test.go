@@ -0,0 +1,108 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/bytebufferpool"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
+)
+
+const traceStatsFormat = "${readHeaderLatency} ${readBodyLatency} ${handleLatency} ${recvSize}"
+
+type fakeEvent struct {
+	t time.Time
+}
+
+func (e fakeEvent) Event() stats.Event   { return nil }
+func (e fakeEvent) Status() stats.Status { return stats.StatusInfo }
+func (e fakeEvent) Info() string         { return "" }
+func (e fakeEvent) Time() time.Time      { return e.t }
+func (e fakeEvent) IsNil() bool          { return false }
+
+// fakeStats returns events at fixed times
+type fakeStats struct {
+	traceinfo.HTTPStats
+	events   map[stats.Event]time.Time
+	recvSize int
+}
+
+func (s *fakeStats) GetEvent(e stats.Event) traceinfo.Event {
+	if t, ok := s.events[e]; ok {
+		return fakeEvent{t: t}
+	}
+	return nil
+}
+
+func (s *fakeStats) RecvSize() int { return s.recvSize }
+
+type fakeTraceInfo struct {
+	traceinfo.TraceInfo
+	stats *fakeStats
+}
+
+func (t fakeTraceInfo) Stats() traceinfo.HTTPStats { return t.stats }
+
+func TestTraceStatsTags(t *testing.T) {
+	c, data := newCLFContext()
+	assert.DeepEqual(t, "- - - -", renderFormat(t, traceStatsFormat, c, data))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	st := &fakeStats{
+		events: map[stats.Event]time.Time{
+			stats.HTTPStart:         start,
+			stats.ReadHeaderStart:   start,
+			stats.ReadHeaderFinish:  start.Add(time.Millisecond),
+			stats.ReadBodyStart:     start.Add(time.Millisecond),
+			stats.ReadBodyFinish:    start.Add(3 * time.Millisecond),
+			stats.ServerHandleStart: start.Add(4 * time.Millisecond),
+		},
+		recvSize: 120,
+	}
+	c.SetTraceInfo(fakeTraceInfo{stats: st})
+	data.Stop = start.Add(14*time.Millisecond + 1500*time.Microsecond)
+	assert.DeepEqual(t, "1ms 2ms 11.5ms 120", renderFormat(t, traceStatsFormat, c, data))
+
+	// the server recorded the end of the handler chain
+	st.events[stats.ServerHandleFinish] = start.Add(15 * time.Millisecond)
+	data.cfg = newOptions(WithLatencyUnit("ms"))
+	assert.DeepEqual(t, "1.000 2.000 11.000 120", renderFormat(t, traceStatsFormat, c, data))
+}
+
+func TestTraceStatsFields(t *testing.T) {
+	c, data := newCLFContext()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.SetTraceInfo(fakeTraceInfo{stats: &fakeStats{
+		events: map[stats.Event]time.Time{
+			stats.HTTPStart:        start,
+			stats.ReadHeaderStart:  start,
+			stats.ReadHeaderFinish: start.Add(1200 * time.Microsecond),
+		},
+		recvSize: 120,
+	}})
+	data.cfg = newOptions(WithJSONFields(TagReadHeaderLatency, TagReadBodyLatency, TagRecvSize))
+	fields, err := data.cfg.encoder.build(data.cfg.fields, Tags)
+	assert.Nil(t, err)
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	assert.Nil(t, data.cfg.encoder.write(buf, fields, c, data))
+	assert.DeepEqual(t, `{"readHeaderLatency":1200000,"readBodyLatency":"-","recvSize":120}`, buf.String())
+}
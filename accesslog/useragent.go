@@ -0,0 +1,320 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	uaCacheSize = 1024
+	// maxCachedUALen keeps oversized agents out of the cache, they are parsed on every request
+	maxCachedUALen = 512
+)
+
+// Device classes written by ${uaDevice}
+const (
+	uaDesktop = "desktop"
+	uaMobile  = "mobile"
+	uaTablet  = "tablet"
+	uaBotDev  = "bot"
+	uaOther   = "other"
+)
+
+// userAgent is the result of parsing a User-Agent header, unknown values are empty
+type userAgent struct {
+	browser string
+	version string
+	os      string
+	device  string
+	bot     bool
+}
+
+// uaRule maps the first product token found in a User-Agent to a name, the version is read
+// after version or, if version is empty, after token
+type uaRule struct {
+	token   string
+	name    string
+	version string
+}
+
+// uaBots are matched before the browsers, the generic markers below catch the other crawlers
+var uaBots = []uaRule{
+	{token: "Googlebot", name: "Googlebot", version: "Googlebot/"},
+	{token: "bingbot", name: "Bingbot", version: "bingbot/"},
+	{token: "Yahoo! Slurp", name: "Yahoo! Slurp"},
+	{token: "DuckDuckBot", name: "DuckDuckBot", version: "DuckDuckBot/"},
+	{token: "Baiduspider", name: "Baiduspider", version: "Baiduspider/"},
+	{token: "YandexBot", name: "YandexBot", version: "YandexBot/"},
+	{token: "Applebot", name: "Applebot", version: "Applebot/"},
+	{token: "facebookexternalhit", name: "facebookexternalhit", version: "facebookexternalhit/"},
+	{token: "Twitterbot", name: "Twitterbot", version: "Twitterbot/"},
+	{token: "AhrefsBot", name: "AhrefsBot", version: "AhrefsBot/"},
+	{token: "SemrushBot", name: "SemrushBot", version: "SemrushBot/"},
+	{token: "GPTBot", name: "GPTBot", version: "GPTBot/"},
+}
+
+// uaBotMarkers are matched at the end of a token, e.g. "examplebot/1.0" or "examplebot;",
+// so that names like the Cubot phones are not taken for crawlers
+var uaBotMarkers = []string{"bot", "crawler", "spider", "slurp"}
+
+// uaClients are libraries and command line tools, they are checked before the browsers.
+// Their tokens are only matched at the start of a product, see matchProduct.
+var uaClients = []uaRule{
+	{token: "curl/", name: "curl"},
+	{token: "Wget/", name: "Wget"},
+	{token: "Go-http-client/", name: "Go-http-client"},
+	// the default User-Agent of the Hertz client is "hertz" without a version
+	{token: "hertz", name: "Hertz", version: "hertz/"},
+	{token: "python-requests/", name: "python-requests"},
+	{token: "Python-urllib/", name: "Python-urllib"},
+	{token: "okhttp/", name: "okhttp"},
+	{token: "Apache-HttpClient/", name: "Apache-HttpClient"},
+	{token: "axios/", name: "axios"},
+	{token: "node-fetch/", name: "node-fetch"},
+	{token: "PostmanRuntime/", name: "PostmanRuntime"},
+}
+
+// uaBrowsers are ordered so that browsers built on Chrome or Safari match before them
+var uaBrowsers = []uaRule{
+	{token: "Edg/", name: "Edge"},
+	{token: "EdgA/", name: "Edge"},
+	{token: "EdgiOS/", name: "Edge"},
+	{token: "OPR/", name: "Opera"},
+	{token: "SamsungBrowser/", name: "Samsung Internet"},
+	{token: "YaBrowser/", name: "Yandex Browser"},
+	{token: "Vivaldi/", name: "Vivaldi"},
+	{token: "FxiOS/", name: "Firefox"},
+	{token: "CriOS/", name: "Chrome"},
+	{token: "Firefox/", name: "Firefox"},
+	{token: "Chromium/", name: "Chromium"},
+	{token: "Chrome/", name: "Chrome"},
+	{token: "Safari/", name: "Safari", version: "Version/"},
+	{token: "MSIE ", name: "Internet Explorer"},
+	{token: "Trident/", name: "Internet Explorer", version: "rv:"},
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// parseUserAgent parses ua with the tables above
+func parseUserAgent(ua string) *userAgent {
+	p := &userAgent{}
+	if ua == "" {
+		return p
+	}
+	p.os = parseOS(ua)
+	if rule, ok := matchRule(ua, uaBots); ok {
+		p.browser, p.version, p.bot = rule.name, ruleVersion(ua, rule), true
+	} else if rule, ok = matchProduct(ua, uaClients); ok {
+		p.browser, p.version, p.device = rule.name, ruleVersion(ua, rule), uaOther
+	} else if rule, ok = matchRule(ua, uaBrowsers); ok {
+		p.browser, p.version = rule.name, ruleVersion(ua, rule)
+	}
+	if !p.bot {
+		lower := strings.ToLower(ua)
+		for _, marker := range uaBotMarkers {
+			if hasBotMarker(lower, marker) {
+				p.bot = true
+				break
+			}
+		}
+		if p.bot && p.browser == "" {
+			p.browser, p.version = productToken(ua)
+		}
+	}
+	if p.bot {
+		p.device = uaBotDev
+	} else if p.device == "" {
+		p.device = parseDevice(ua, p.os)
+	}
+	return p
+}
+
+func matchRule(ua string, rules []uaRule) (uaRule, bool) {
+	for _, rule := range rules {
+		if strings.Contains(ua, rule.token) {
+			return rule, true
+		}
+	}
+	return uaRule{}, false
+}
+
+// matchProduct is like matchRule but only matches the tokens at the start of ua or after a
+// space, tokens not ending with "/" must also be followed by "/", a space or the end of ua
+func matchProduct(ua string, rules []uaRule) (uaRule, bool) {
+	for _, rule := range rules {
+		for i := 0; i < len(ua); {
+			j := strings.Index(ua[i:], rule.token)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(rule.token)
+			if (start == 0 || ua[start-1] == ' ') &&
+				(strings.HasSuffix(rule.token, "/") || end == len(ua) || ua[end] == '/' || ua[end] == ' ') {
+				return rule, true
+			}
+			i = start + 1
+		}
+	}
+	return uaRule{}, false
+}
+
+// hasBotMarker reports whether marker ends a token of the lower-cased ua, i.e. is followed by
+// "/", ";" or the end of ua
+func hasBotMarker(lower, marker string) bool {
+	for i := 0; i < len(lower); {
+		j := strings.Index(lower[i:], marker)
+		if j < 0 {
+			return false
+		}
+		end := i + j + len(marker)
+		if end == len(lower) || lower[end] == '/' || lower[end] == ';' {
+			return true
+		}
+		i = end
+	}
+	return false
+}
+
+// ruleVersion returns the digits and dots following the version prefix of rule, rules whose
+// prefix does not end with a separator have no version
+func ruleVersion(ua string, rule uaRule) string {
+	prefix := rule.version
+	if prefix == "" {
+		prefix = rule.token
+	}
+	if last := prefix[len(prefix)-1]; last != '/' && last != ' ' && last != ':' {
+		return ""
+	}
+	if i := strings.Index(ua, prefix); i >= 0 {
+		return versionAt(ua, i+len(prefix), '.')
+	}
+	return ""
+}
+
+// versionAt returns the version at the start of ua[i:], made of digits and sep
+func versionAt(ua string, i int, sep byte) string {
+	j := i
+	for j < len(ua) && (ua[j] >= '0' && ua[j] <= '9' || ua[j] == sep) {
+		j++
+	}
+	return strings.TrimRight(ua[i:j], string(sep))
+}
+
+// productToken returns the name and version of the first product of ua, e.g. "Foo" and "1.2" for "Foo/1.2 (...)"
+func productToken(ua string) (string, string) {
+	end := strings.IndexAny(ua, " (;")
+	if end < 0 {
+		end = len(ua)
+	}
+	token := ua[:end]
+	if i := strings.IndexByte(token, '/'); i >= 0 {
+		return token[:i], versionAt(token, i+1, '.')
+	}
+	return token, ""
+}
+
+func parseOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad") || strings.Contains(ua, "iPod"):
+		if i := strings.Index(ua, " OS "); i >= 0 {
+			if v := versionAt(ua, i+len(" OS "), '_'); v != "" {
+				return "iOS " + strings.Replace(v, "_", ".", -1)
+			}
+		}
+		return "iOS"
+	case strings.Contains(ua, "Android"):
+		if i := strings.Index(ua, "Android "); i >= 0 {
+			if v := versionAt(ua, i+len("Android "), '.'); v != "" {
+				return "Android " + v
+			}
+		}
+		return "Android"
+	case strings.Contains(ua, "CrOS"):
+		return "Chrome OS"
+	case strings.Contains(ua, "Mac OS X"):
+		if i := strings.Index(ua, "Mac OS X "); i >= 0 {
+			if v := versionAt(ua, i+len("Mac OS X "), '_'); v != "" {
+				return "macOS " + strings.Replace(v, "_", ".", -1)
+			}
+		}
+		return "macOS"
+	case strings.Contains(ua, "Windows"):
+		if i := strings.Index(ua, "Windows NT "); i >= 0 {
+			if v, ok := windowsVersions[versionAt(ua, i+len("Windows NT "), '.')]; ok {
+				return "Windows " + v
+			}
+		}
+		return "Windows"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
+
+func parseDevice(ua, os string) string {
+	switch {
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobi"):
+		return uaTablet
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPod"):
+		return uaMobile
+	case os != "" && !strings.HasPrefix(os, "iOS") && !strings.HasPrefix(os, "Android"):
+		return uaDesktop
+	}
+	return uaOther
+}
+
+// requestUserAgent returns the parsed User-Agent of the request, it is parsed once per request
+// and cached by the instance
+func requestUserAgent(c *app.RequestContext, data *Data) *userAgent {
+	if data.userAgent != nil {
+		return data.userAgent
+	}
+	ua := c.Request.Header.UserAgent()
+	cache := data.options().uaCache
+	if cache == nil || len(ua) > maxCachedUALen {
+		data.userAgent = parseUserAgent(string(ua))
+		return data.userAgent
+	}
+	if v, ok := cache.get(unsafeString(ua)); ok {
+		data.userAgent = v.(*userAgent)
+		return data.userAgent
+	}
+	key := string(ua)
+	data.userAgent = parseUserAgent(key)
+	cache.add(key, data.userAgent)
+	return data.userAgent
+}
+
+// writeUserAgent writes the field of the parsed User-Agent picked by get, or "-" if it is
+// unknown or the User-Agent header is redacted
+func writeUserAgent(output Buffer, c *app.RequestContext, data *Data, get func(*userAgent) string) (int, error) {
+	if data.options().redactor.header([]byte("User-Agent")) {
+		return output.WriteString("-")
+	}
+	return writeOrDash(output, get(requestUserAgent(c, data)))
+}
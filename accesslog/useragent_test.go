@@ -0,0 +1,129 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	for ua, want := range map[string]userAgent{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36": {
+			browser: "Chrome", version: "120.0.0.0", os: "Windows 10", device: uaDesktop,
+		},
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91": {
+			browser: "Edge", version: "120.0.2210.91", os: "Windows 10", device: uaDesktop,
+		},
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15": {
+			browser: "Safari", version: "17.1", os: "macOS 10.15.7", device: uaDesktop,
+		},
+		"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0": {
+			browser: "Firefox", version: "121.0", os: "Linux", device: uaDesktop,
+		},
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1": {
+			browser: "Safari", version: "17.1", os: "iOS 17.1.2", device: uaMobile,
+		},
+		"Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/119.0.6045.169 Mobile/15E148 Safari/604.1": {
+			browser: "Chrome", version: "119.0.6045.169", os: "iOS 16.6", device: uaTablet,
+		},
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.43 Mobile Safari/537.36": {
+			browser: "Chrome", version: "120.0.6099.43", os: "Android 14", device: uaMobile,
+		},
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Safari/537.36": {
+			browser: "Samsung Internet", version: "23.0", os: "Android 13", device: uaTablet,
+		},
+		"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko": {
+			browser: "Internet Explorer", version: "11.0", os: "Windows 7", device: uaDesktop,
+		},
+		"Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0": {
+			browser: "Opera", version: "106.0.0.0", os: "Chrome OS", device: uaDesktop,
+		},
+		"curl/8.4.0": {
+			browser: "curl", version: "8.4.0", device: uaOther,
+		},
+		"Go-http-client/1.1": {
+			browser: "Go-http-client", version: "1.1", device: uaOther,
+		},
+		"hertz": {
+			browser: "Hertz", device: uaOther,
+		},
+		"hertz/0.7.2": {
+			browser: "Hertz", version: "0.7.2", device: uaOther,
+		},
+		"Mozilla/5.0 (Linux; Android 10; Cubot X30) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Mobile Safari/537.36": {
+			browser: "Chrome", version: "91.0.4472.120", os: "Android 10", device: uaMobile,
+		},
+		"Mozilla/5.0 (Linux; Android 9; CUBOT_P30 Build/PPR1.180610.011) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/74.0.3729.157 Mobile Safari/537.36": {
+			browser: "Chrome", version: "74.0.3729.157", os: "Android 9", device: uaMobile,
+		},
+		"myhertzapp/1.0": {
+			device: uaOther,
+		},
+		"Mozilla/5.0 (X11; Linux x86_64) Chrome/120.0.0.0 Safari/537.36 hertz-lab": {
+			browser: "Chrome", version: "120.0.0.0", os: "Linux", device: uaDesktop,
+		},
+		"ExampleBot/1.0": {
+			browser: "ExampleBot", version: "1.0", device: uaBotDev, bot: true,
+		},
+		"Mozilla/5.0 (compatible; PetalBot;+https://webmaster.petalsearch.com/site/petalbot)": {
+			browser: "Mozilla", version: "5.0", device: uaBotDev, bot: true,
+		},
+		"python-requests/2.31.0": {
+			browser: "python-requests", version: "2.31.0", device: uaOther,
+		},
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": {
+			browser: "Googlebot", version: "2.1", device: uaBotDev, bot: true,
+		},
+		"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm) Chrome/116.0.1938.76 Safari/537.36": {
+			browser: "Bingbot", version: "2.0", device: uaBotDev, bot: true,
+		},
+		"ExampleCrawler/3.2 (+https://example.com/crawler)": {
+			browser: "ExampleCrawler", version: "3.2", device: uaBotDev, bot: true,
+		},
+		"": {},
+		"unknown agent": {
+			device: uaOther,
+		},
+	} {
+		assert.DeepEqual(t, want, *parseUserAgent(ua))
+	}
+}
+
+func TestUserAgentTags(t *testing.T) {
+	format := "${uaBrowser}|${uaBrowserVersion}|${uaOS}|${uaDevice}|${uaBot}"
+	c, data := newCLFContext()
+	c.Request.Header.SetUserAgentBytes([]byte("curl/8.4.0"))
+	assert.DeepEqual(t, "curl|8.4.0|-|other|false", renderFormat(t, format, c, data))
+
+	// the agent is parsed once per request
+	c.Request.Header.SetUserAgentBytes([]byte("Mozilla/5.0 (compatible; Googlebot/2.1)"))
+	assert.DeepEqual(t, "curl|8.4.0|-|other|false", renderFormat(t, format, c, data))
+
+	data.userAgent = nil
+	data.cfg = newOptions()
+	data.cfg.uaCache = newLRU(uaCacheSize)
+	assert.DeepEqual(t, "Googlebot|2.1|-|bot|true", renderFormat(t, format, c, data))
+	cached, ok := data.cfg.uaCache.get("Mozilla/5.0 (compatible; Googlebot/2.1)")
+	assert.True(t, ok)
+	assert.True(t, cached == data.userAgent)
+
+	data.userAgent = nil
+	data.cfg = newOptions(WithRedaction(Redaction{Headers: []string{"User-Agent"}}))
+	assert.DeepEqual(t, "-|-|-|-|-", renderFormat(t, format, c, data))
+}